@@ -0,0 +1,73 @@
+package algorithm
+
+import (
+	"errors"
+	"github.com/davidafox/rubikscubesolver/notation"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+)
+
+var ErrUnknownPlane = errors.New("Mirror plane must be M, E or S")
+
+//planeAxes holds the faces on either side of each mirror plane
+var planeAxes = map[byte]string{
+	'M': "RLx",
+	'E': "UDy",
+	'S': "FBz",
+}
+
+//Inverse returns the moves that undo seq
+func Inverse(seq notation.Sequence) notation.Sequence {
+	return seq.Inverse()
+}
+
+//Mirror returns seq reflected in the M, E or S plane.
+//The faces either side of the plane swap, and every move turns the other way apart from
+//the slice and rotation that turn around the axis through the plane, so R U R' mirrored in M is L' U' L.
+func Mirror(seq notation.Sequence, plane byte) (notation.Sequence, error) {
+	axis, ok := planeAxes[plane]
+	if !ok {
+		return nil, ErrUnknownPlane
+	}
+	result := make(notation.Sequence, len(seq))
+	for i, m := range seq {
+		switch m.Face {
+		case axis[0]:
+			m.Face = axis[1]
+		case axis[1]:
+			m.Face = axis[0]
+		}
+		if m.Face != plane && m.Face != axis[2] {
+			m.Turns = 4 - m.Turns
+		}
+		result[i] = m
+	}
+	return result, nil
+}
+
+//Conjugate returns [a: b], a b a'
+func Conjugate(a, b notation.Sequence) notation.Sequence {
+	result := append(append(notation.Sequence{}, a...), b...)
+	return append(result, a.Inverse()...)
+}
+
+//Commutator returns [a, b], a b a' b'
+func Commutator(a, b notation.Sequence) notation.Sequence {
+	return append(Conjugate(a, b), b.Inverse()...)
+}
+
+//Repeat returns seq repeated n times, a negative n repeats the inverse
+func Repeat(seq notation.Sequence, n int) notation.Sequence {
+	if n < 0 {
+		seq, n = seq.Inverse(), -n
+	}
+	result := make(notation.Sequence, 0, len(seq)*n)
+	for i := 0; i < n; i++ {
+		result = append(result, seq...)
+	}
+	return result
+}
+
+//Apply turns c by seq, wide moves, slices and rotations are applied as face turns so c may end up rotated
+func Apply(c rubikscuberunner.OfficialCube, seq notation.Sequence) {
+	rubikscuberunner.NewOfficialRunner(c).RunSequence(seq)
+}
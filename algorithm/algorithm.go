@@ -0,0 +1,78 @@
+package algorithm
+
+import (
+	"github.com/davidafox/rubikscubesolver/notation"
+	"sort"
+	"strings"
+)
+
+//algorithm normalizes move sequences so the same moves always give the same text.
+//Inverse moves cancel, turns of the same face merge, and turns of opposite faces, which don't affect
+//each other, are put in the order U D R L F B.
+
+//order holds the position of each face in the canonical order, opposite faces are next to each other
+const order = "UDRLFB"
+
+//axis returns the axis a face turn turns around or -1 for any other move
+func axis(m notation.Move) int {
+	i := strings.IndexByte(order, m.Face)
+	if i < 0 || m.Wide || m.Inner {
+		return -1
+	}
+	return i / 2
+}
+
+//commute reports whether a and b are face turns of opposite faces, or the same face, so their order doesn't matter
+func commute(a, b notation.Move) bool {
+	return axis(a) >= 0 && axis(a) == axis(b)
+}
+
+//Simplify returns seq with inverse moves cancelled, turns of the same face merged and turns of opposite faces in canonical order.
+//The result does the same thing to a cube as seq.
+func Simplify(seq notation.Sequence) notation.Sequence {
+	result := notation.Sequence{}
+	for _, m := range seq {
+		merged := false
+		//a move can merge with an earlier turn of the same face when only turns of the opposite face are between them
+		for i := len(result) - 1; i >= 0; i-- {
+			x := result[i]
+			if x.Face == m.Face && x.Wide == m.Wide && x.Inner == m.Inner {
+				x.Turns = (x.Turns + m.Turns) % 4
+				if x.Turns == 0 {
+					result = append(result[:i], result[i+1:]...)
+				} else {
+					result[i] = x
+				}
+				merged = true
+				break
+			}
+			if !commute(x, m) {
+				break
+			}
+		}
+		if !merged && m.Turns%4 != 0 {
+			result = append(result, m)
+		}
+	}
+	for start := 0; start < len(result); {
+		end := start + 1
+		for end < len(result) && commute(result[start], result[end]) {
+			end++
+		}
+		run := result[start:end]
+		sort.Slice(run, func(i, j int) bool {
+			return strings.IndexByte(order, run[i].Face) < strings.IndexByte(order, run[j].Face)
+		})
+		start = end
+	}
+	return result
+}
+
+//SimplifyString parses s as official notation and returns it simplified
+func SimplifyString(s string) (string, error) {
+	seq, err := notation.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return Simplify(seq).String(), nil
+}
@@ -0,0 +1,142 @@
+package algorithm
+
+import (
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/notation"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"math/rand"
+	"testing"
+)
+
+func TestSimplify(t *testing.T) {
+	data := []struct {
+		s        string
+		expected string
+	}{
+		{"R U F", "R U F"},
+		{"R R'", ""},
+		{"U U2", "U'"},
+		{"U U U", "U'"},
+		{"R   U  U'  R'", ""},
+		{"R L R'", "L"},
+		{"D U", "U D"},
+		{"L R2 L' R2", ""},
+		{"F B F", "F2 B"},
+		{"B F' U D' U2", "F' B U' D'"},
+		{"Rw Rw R", "Rw2 R"},
+		{"Rw L", "Rw L"},
+		{"M M'", ""},
+		{"x R x'", "x R x'"},
+		{"R U R' U' U R U' R'", ""},
+		{"2R 2R 2R2", ""},
+		{"2R R 2R' R'", "2R R 2R' R'"},
+		{"R 2R 2R' R'", ""},
+	}
+	for _, x := range data {
+		got, err := SimplifyString(x.s)
+		if err != nil {
+			t.Error("Failed SimplifyString for ", x.s, " got error: ", err)
+			continue
+		}
+		if got != x.expected {
+			t.Error("Failed SimplifyString for ", x.s, " got: ", got, " expected: ", x.expected)
+		}
+	}
+	if _, err := SimplifyString("R Q"); err == nil {
+		t.Error("Failed SimplifyString for R Q expected an error")
+	}
+}
+
+func TestSimplifyState(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	faces := "UDRLFB"
+	for i := 0; i < 500; i++ {
+		seq := notation.Sequence{}
+		for j := 0; j < 12; j++ {
+			//only four faces so moves often cancel
+			seq = append(seq, notation.Move{Face: faces[rng.Intn(4)], Turns: rng.Intn(3) + 1})
+		}
+		simplified := Simplify(seq)
+		if len(Simplify(simplified)) != len(simplified) || Simplify(simplified).String() != simplified.String() {
+			t.Error("Failed Simplify for ", seq, " is not stable: ", simplified)
+		}
+		for k := 0; k+1 < len(simplified); k++ {
+			if simplified[k].Face == simplified[k+1].Face {
+				t.Error("Failed Simplify for ", seq, " got: ", simplified)
+			}
+		}
+		a, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+		rubikscuberunner.NewOfficialRunner(a).RunSequence(seq)
+		b, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+		rubikscuberunner.NewOfficialRunner(b).RunSequence(simplified)
+		if a.String() != b.String() {
+			t.Error("Failed Simplify for ", seq, " got: ", simplified)
+		}
+	}
+}
+
+func TestAlgebra(t *testing.T) {
+	a, b := notation.MustParse("R U"), notation.MustParse("F2 D'")
+	data := []struct {
+		got      notation.Sequence
+		expected string
+	}{
+		{Inverse(a), "U' R'"},
+		{Conjugate(a, b), "R U F2 D' U' R'"},
+		{Commutator(a, b), "R U F2 D' U' R' D F2"},
+		{Repeat(a, 3), "R U R U R U"},
+		{Repeat(a, -2), "U' R' U' R'"},
+		{Repeat(a, 0), ""},
+	}
+	for i, x := range data {
+		if x.got.String() != x.expected {
+			t.Error("Failed algebra case ", i, " got: ", x.got, " expected: ", x.expected)
+		}
+	}
+	parsed := notation.MustParse("[R U, F2 D']")
+	if parsed.String() != Commutator(a, b).String() {
+		t.Error("Failed Commutator does not match the parser got: ", parsed)
+	}
+}
+
+func TestMirror(t *testing.T) {
+	data := []struct {
+		s        string
+		plane    byte
+		expected string
+	}{
+		{"R U R'", 'M', "L' U' L"},
+		{"Rw M x E S y z", 'M', "Lw' M x E' S' y' z'"},
+		{"U R D'", 'E', "D' R' U"},
+		{"M E S x y z", 'E', "M' E S' x' y z'"},
+		{"F R B2", 'S', "B' R' F2"},
+	}
+	for _, x := range data {
+		got, err := Mirror(notation.MustParse(x.s), x.plane)
+		if err != nil || got.String() != x.expected {
+			t.Error("Failed Mirror for ", x.s, " in ", string(x.plane), " got: ", got, err, " expected: ", x.expected)
+		}
+	}
+	if _, err := Mirror(notation.MustParse("R"), 'x'); err != ErrUnknownPlane {
+		t.Error("Failed Mirror got: ", err, " expected: ", ErrUnknownPlane)
+	}
+	//a mirrored sequence leaves the cube reflected, which is a bytecube symmetry
+	rng := rand.New(rand.NewSource(1))
+	faces := "UDRLFB"
+	for plane, sym := range map[byte]int{'M': 1, 'E': 2, 'S': 4} {
+		for i := 0; i < 50; i++ {
+			seq := notation.Sequence{}
+			for j := 0; j < 10; j++ {
+				seq = append(seq, notation.Move{Face: faces[rng.Intn(6)], Turns: rng.Intn(3) + 1})
+			}
+			mirrored, _ := Mirror(seq, plane)
+			c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+			Apply(c, seq)
+			m, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+			Apply(m, mirrored)
+			if c.State().Transform(sym) != m.State() {
+				t.Error("Failed Mirror for ", seq, " in ", string(plane), " got: ", mirrored)
+			}
+		}
+	}
+}
@@ -1,8 +1,9 @@
 package basic
 
 import (
-	"fmt"
+	"context"
 	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/solver"
 	"log"
 	"os"
 	"runtime"
@@ -40,11 +41,22 @@ func NewFactory(size int) *Factory {
 	return f
 }
 
+func init() {
+	solver.Register("basic", func(ctx context.Context, c *bytecube.Cube, o solver.Options) (solver.Solver, error) {
+		s, err := NewSolver(c.String(), o.Target, 3, NewFactory(3))
+		if err != nil {
+			return nil, err
+		}
+		return solver.Legacy(s), nil
+	})
+}
+
 type Solver struct {
 	startingState bytecube.State
 	size          int
 	factory       CubeFactory
 	foundStates   map[bytecube.State]bool
+	observer      solver.Observer
 }
 
 type cubeState struct {
@@ -52,14 +64,20 @@ type cubeState struct {
 	steps string
 }
 
-func NewSolver(startingState string, size int, factory CubeFactory) *Solver {
+//NewSolver returns a solver of the steps from startingState to target, or to the solved cube when target is "".
+//Once turned into official face turns by solver.Legacy they reach target exactly, see solver.Relative.
+func NewSolver(startingState, target string, size int, factory CubeFactory) (*Solver, error) {
+	startingState, err := solver.RelativeState(startingState, target)
+	if err != nil {
+		return nil, err
+	}
 	s := new(Solver)
 	s.size = size
 	s.factory = factory
 	c, _ := bytecube.NewCube(startingState)
 	s.startingState = c.State()
 	s.foundStates = make(map[bytecube.State]bool)
-	return s
+	return s, nil
 }
 
 func newCubeState(state bytecube.State, steps string) *cubeState {
@@ -69,7 +87,19 @@ func newCubeState(state bytecube.State, steps string) *cubeState {
 	return c
 }
 
+//SetObserver sets the observer that receives the progress of the solve, by default nothing is reported
+func (s *Solver) SetObserver(o solver.Observer) {
+	s.observer = o
+}
+
 func (s *Solver) Solve() string {
+	solution, _ := s.SolveContext(context.Background())
+	return solution
+}
+
+//SolveContext stops the search and returns a *solver.CancelError when ctx is canceled or its deadline passes
+func (s *Solver) SolveContext(ctx context.Context) (string, error) {
+	stop := solver.NewStopper(ctx)
 	states := make([]*cubeState, 1, 1)
 	states[0] = newCubeState(s.startingState, "")
 	s.foundStates[s.startingState] = true
@@ -77,26 +107,41 @@ func (s *Solver) Solve() string {
 	for i := 0; ; i++ {
 		currentStates := currentStates[:0]
 		for _, x := range states {
+			if stop.Stopped() {
+				s.release()
+				return "", stop.Err()
+			}
 			nstates, solved := s.nextStates(x, false)
 			if solved {
-				return nstates[0].steps
+				return s.found(nstates[0].steps)
 			}
 			currentStates = append(currentStates, nstates...)
 		}
-		if i%1 == 0 {
-			fmt.Println("On step ", i, "states: ", len(currentStates))
-		}
 		states, currentStates = currentStates, states
+		s.observer.Notify(solver.Event{Kind: solver.LayerDone, Depth: i, Frontier: [2]int{len(states)}})
 	}
 }
 
 func (s *Solver) SolveConcurrent() string {
+	solution, _ := s.SolveConcurrentContext(context.Background())
+	return solution
+}
+
+//SolveConcurrentContext stops the search and returns a *solver.CancelError when ctx is canceled or its deadline passes
+func (s *Solver) SolveConcurrentContext(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := solver.NewStopper(ctx)
 	states := make([]*cubeState, 1, 1)
 	states[0] = newCubeState(s.startingState, "")
 	s.foundStates[s.startingState] = true
 	res := make(chan *results)
-	workers := s.spawnWorkers(res)
+	workers := s.spawnWorkers(ctx, res)
 	for i := 0; ; i++ {
+		if stop.Done() {
+			s.release()
+			return "", stop.Err()
+		}
 		currentStates := make([]*cubeState, 0, 10)
 		workersRunning := 0
 		jobsPerWorker := len(states) / len(workers)
@@ -114,7 +159,15 @@ func (s *Solver) SolveConcurrent() string {
 			}
 		}
 		for k := 0; k < workersRunning; k++ {
-			result := <-res
+			var result *results
+			select {
+			case result = <-res:
+			case <-ctx.Done():
+			}
+			if stop.Done() {
+				s.release()
+				return "", stop.Err()
+			}
 			if result.solved {
 				f, err := os.Create("main.mprof")
 				if err != nil {
@@ -125,7 +178,7 @@ func (s *Solver) SolveConcurrent() string {
 				for _, x := range workers {
 					close(x)
 				}
-				return result.states[0].steps
+				return s.found(result.states[0].steps)
 			}
 			for _, x := range result.states {
 				if ok := s.foundStates[x.state]; !ok {
@@ -134,17 +187,28 @@ func (s *Solver) SolveConcurrent() string {
 				}
 			}
 		}
-		fmt.Println("On step ", i, "states: ", len(currentStates))
 		states, currentStates = currentStates, states
+		s.observer.Notify(solver.Event{Kind: solver.LayerDone, Depth: i, Frontier: [2]int{len(states)}})
 	}
 }
 
-func (s *Solver) spawnWorkers(res chan *results) []chan []*cubeState {
+//found reports the solution to the observer, every step is a letter and a row
+func (s *Solver) found(solution string) (string, error) {
+	s.observer.Notify(solver.Event{Kind: solver.SolutionFound, Depth: len(solution) / 2, Solution: solution})
+	return solution, nil
+}
+
+//release drops the found states of a stopped solve
+func (s *Solver) release() {
+	s.foundStates = make(map[bytecube.State]bool)
+}
+
+func (s *Solver) spawnWorkers(ctx context.Context, res chan *results) []chan []*cubeState {
 	channels := make([]chan []*cubeState, 0, 0)
 	for i := 0; i < runtime.GOMAXPROCS(-1); i++ {
 		c := make(chan []*cubeState)
 		channels = append(channels, c)
-		go s.NewWorker(res, c)
+		go s.NewWorker(ctx, res, c)
 	}
 	return channels
 }
@@ -154,18 +218,39 @@ type results struct {
 	solved bool
 }
 
-func (s *Solver) NewWorker(res chan *results, in chan []*cubeState) {
-	for states := range in {
+//NewWorker expands the lists of states it receives until in is closed or ctx is done
+func (s *Solver) NewWorker(ctx context.Context, res chan *results, in chan []*cubeState) {
+	for {
+		var states []*cubeState
+		select {
+		case l, ok := <-in:
+			if !ok {
+				return
+			}
+			states = l
+		case <-ctx.Done():
+			return
+		}
 		resultStates := make([]*cubeState, 0)
-		for _, state := range states {
+		for i, state := range states {
+			if i%1024 == 0 && ctx.Err() != nil {
+				return
+			}
 			nstates, solved := s.nextStates(state, true)
 			if solved {
-				res <- &results{nstates, solved}
+				select {
+				case res <- &results{nstates, solved}:
+				case <-ctx.Done():
+				}
 				return
 			}
 			resultStates = append(resultStates, nstates...)
 		}
-		res <- &results{resultStates, false}
+		select {
+		case res <- &results{resultStates, false}:
+		case <-ctx.Done():
+			return
+		}
 	}
 }
 
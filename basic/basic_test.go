@@ -1,19 +1,43 @@
 package basic
 
 import (
+	"context"
+	"errors"
+	"github.com/davidafox/rubikscubesolver/bytecube"
 	"github.com/davidafox/rubikscubesolver/rubikscube"
 	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"github.com/davidafox/rubikscubesolver/solver"
 	"testing"
+	"time"
 )
 
 func TestSolve(t *testing.T) {
 	c := rubikscube.NewCube("000000000111111111222222222333333333444444444555555555", 3)
 	r := rubikscuberunner.NewRunner(c)
 	r.Run("R1L1U0C1L0L0C1L0")
-	s := NewSolver(c.String(), 3, NewFactory(3))
+	s, err := NewSolver(c.String(), "", 3, NewFactory(3))
+	if err != nil {
+		t.Fatal(err)
+	}
 	result := s.Solve()
 	r.Run(result)
 	if !c.Solved() {
 		t.Error("Failed to solve got: ", c.String())
 	}
 }
+
+func TestSolveConcurrentContext(t *testing.T) {
+	c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+	rubikscuberunner.NewRunner(c).Run("C1L0R1U0T1T0R1U0C1R0T0")
+	s, err := NewSolver(c.String(), "", 3, NewFactory(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	result, err := s.SolveConcurrentContext(ctx)
+	var cancelErr *solver.CancelError
+	if !errors.As(err, &cancelErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("Failed SolveConcurrentContext got: ", result, err, " expected: ", context.DeadlineExceeded)
+	}
+}
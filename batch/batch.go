@@ -0,0 +1,262 @@
+package batch
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/format"
+	"github.com/davidafox/rubikscubesolver/notation"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"github.com/davidafox/rubikscubesolver/solver"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//batch solves a list of cubes without a prompt.
+//Each input line is a 54 digit state, or a JSON object with a "state" or a "scramble" and an optional "id".
+//Blank lines and lines starting with # are skipped. The results are written in the order of the input, a line that
+//can't be read gets a result with its error.
+
+var ErrNotSolved = errors.New("The solution does not solve the cube")
+var ErrStateAndScramble = errors.New("Give a state or a scramble, not both")
+
+//Item is a cube to solve
+type Item struct {
+	//Line is the line of the input the item was read from, counting from 1
+	Line     int    `json:"-"`
+	ID       string `json:"id,omitempty"`
+	State    string `json:"state,omitempty"`
+	Scramble string `json:"scramble,omitempty"`
+	//Err is the error reading the line, the item is not solved when it is set
+	Err error `json:"-"`
+}
+
+//Result is the outcome of solving an item, Error is empty when the solution was verified
+type Result struct {
+	Line      int     `json:"line"`
+	ID        string  `json:"id,omitempty"`
+	State     string  `json:"state"`
+	Solution  string  `json:"solution"`
+	Moves     int     `json:"moves"`
+	ElapsedMS float64 `json:"elapsed_ms"`
+	Verified  bool    `json:"verified"`
+	Error     string  `json:"error,omitempty"`
+}
+
+//Config holds the settings of a batch, zero values mean the defaults
+type Config struct {
+	//Solver is the registered solver to use, combined by default
+	Solver  string
+	Options solver.Options
+	//Parallel is the number of cubes solved at once, 1 by default
+	Parallel int
+	//Timeout is the longest a single solve may take, 0 for no limit
+	Timeout time.Duration
+}
+
+//Read reads the items of r, a line starting with { that isn't a JSON object gives an item with Err set
+func Read(r io.Reader) ([]Item, error) {
+	var items []Item
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		item := Item{Line: line}
+		if strings.HasPrefix(text, "{") {
+			if err := json.Unmarshal([]byte(text), &item); err != nil {
+				item.Err = err
+			}
+			item.Line = line
+		} else {
+			item.State = text
+		}
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
+
+//Solve solves the items and passes each result to emit in the order of the items.
+//It stops early when ctx is done or emit returns an error.
+func Solve(ctx context.Context, items []Item, config Config, emit func(*Result) error) error {
+	if config.Solver == "" {
+		config.Solver = "combined"
+	}
+	if config.Parallel <= 0 {
+		config.Parallel = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	jobs := make(chan int)
+	results := make([]chan *Result, len(items))
+	for i := range results {
+		results[i] = make(chan *Result, 1)
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < config.Parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] <- solve(ctx, items[i], config)
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for i := range items {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	defer func() {
+		//the workers' solves are canceled so they finish quickly when the batch stops early
+		cancel()
+		wg.Wait()
+	}()
+	for i := range items {
+		var r *Result
+		select {
+		case r = <-results[i]:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if err := emit(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//solve solves one item and checks the solution with OfficialRunner
+func solve(ctx context.Context, item Item, config Config) *Result {
+	r := &Result{Line: item.Line, ID: item.ID, State: item.State}
+	if item.Err != nil {
+		r.Error = item.Err.Error()
+		return r
+	}
+	c, err := cube(item)
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	r.State = c.String()
+	if config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	solution := ""
+	if !c.Solved() {
+		s, err := solver.NewContext(ctx, config.Solver, c, config.Options)
+		if err == nil {
+			solution, err = s.SolveContext(ctx)
+		}
+		if err != nil {
+			r.ElapsedMS = float64(time.Since(start)) / float64(time.Millisecond)
+			r.Error = err.Error()
+			return r
+		}
+	}
+	r.ElapsedMS = float64(time.Since(start)) / float64(time.Millisecond)
+	r.Solution = solution
+	r.Moves = len(strings.Fields(solution))
+	check := bytecube.NewWithState(c.State())
+	if err := rubikscuberunner.NewOfficialRunner(check).Run(solution); err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	r.Verified = check.Solved()
+	if !r.Verified {
+		r.Error = ErrNotSolved.Error()
+	}
+	return r
+}
+
+//cube returns the valid cube of an item
+func cube(item Item) (*bytecube.Cube, error) {
+	if item.State != "" && item.Scramble != "" {
+		return nil, ErrStateAndScramble
+	}
+	if item.Scramble != "" {
+		seq, err := notation.Parse(item.Scramble)
+		if err != nil {
+			return nil, err
+		}
+		c, _ := bytecube.NewCube(bytecube.SOLVED)
+		rubikscuberunner.NewOfficialRunner(c).RunSequence(seq)
+		return c, nil
+	}
+	return format.NewValidCube(item.State)
+}
+
+//Writer writes results in an output format
+type Writer interface {
+	Write(r *Result) error
+	Flush() error
+}
+
+type jsonWriter struct {
+	e *json.Encoder
+}
+
+//NewJSONWriter returns a writer of a JSON object per line
+func NewJSONWriter(w io.Writer) Writer {
+	return &jsonWriter{json.NewEncoder(w)}
+}
+
+func (j *jsonWriter) Write(r *Result) error {
+	return j.e.Encode(r)
+}
+
+func (j *jsonWriter) Flush() error {
+	return nil
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+//CSV_HEADER holds the columns written by a CSV writer
+var CSV_HEADER = []string{"line", "id", "state", "solution", "moves", "elapsed_ms", "verified", "error"}
+
+//NewCSVWriter returns a writer of CSV with a header row
+func NewCSVWriter(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) Write(r *Result) error {
+	if !c.header {
+		c.header = true
+		if err := c.w.Write(CSV_HEADER); err != nil {
+			return err
+		}
+	}
+	return c.w.Write([]string{
+		strconv.Itoa(r.Line),
+		r.ID,
+		r.State,
+		r.Solution,
+		strconv.Itoa(r.Moves),
+		strconv.FormatFloat(r.ElapsedMS, 'f', 3, 64),
+		strconv.FormatBool(r.Verified),
+		r.Error,
+	})
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,123 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	_ "github.com/davidafox/rubikscubesolver/twophase"
+	"strings"
+	"testing"
+)
+
+const input = `# states to solve
+400400214410210233112425535122133333344244155550550120
+
+{"id": "sexy", "scramble": "R U R' U'"}
+{"id": "solved", "state": "000000000111111111222222222333333333444444444555555555"}
+003000000111111111222222222433333333444444440555555555
+{"scramble": "R Q"}
+{"id": "bad", "state": 5}
+{"id": "both", "state": "000000000111111111222222222333333333444444444555555555", "scramble": "R"}
+{"state": "0
+`
+
+func TestRead(t *testing.T) {
+	items, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatal("Failed Read got error: ", err)
+	}
+	lines := []int{2, 4, 5, 6, 7, 8, 9, 10}
+	if len(items) != len(lines) {
+		t.Fatal("Failed Read got: ", items)
+	}
+	for i, x := range items {
+		if x.Line != lines[i] {
+			t.Error("Failed Read item ", i, " got line: ", x.Line, " expected: ", lines[i])
+		}
+	}
+	if items[1].ID != "sexy" || items[1].Scramble != "R U R' U'" {
+		t.Error("Failed Read got: ", items[1])
+	}
+	//a line of bad JSON is still an item so the rest of the input is read
+	if items[5].Err == nil || items[5].ID != "bad" || items[7].Err == nil || items[6].Err != nil {
+		t.Error("Failed Read of bad JSON got: ", items[5], items[6], items[7])
+	}
+}
+
+func TestSolve(t *testing.T) {
+	items, _ := Read(strings.NewReader(input))
+	var buf bytes.Buffer
+	w := NewJSONWriter(&buf)
+	err := Solve(context.Background(), items, Config{Solver: "twophase", Parallel: 3}, w.Write)
+	if err != nil {
+		t.Fatal("Failed Solve got error: ", err)
+	}
+	var results []Result
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var r Result
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatal("Failed Solve wrote: ", line)
+		}
+		results = append(results, r)
+	}
+	if len(results) != len(items) {
+		t.Fatal("Failed Solve got: ", results)
+	}
+	for i, r := range results {
+		if r.Line != items[i].Line {
+			t.Error("Failed Solve results out of order got line: ", r.Line, " expected: ", items[i].Line)
+		}
+	}
+	for _, i := range []int{0, 1, 2} {
+		if !results[i].Verified || results[i].Error != "" {
+			t.Error("Failed Solve got: ", results[i])
+		}
+	}
+	if results[2].Moves != 0 || results[1].ID != "sexy" {
+		t.Error("Failed Solve got: ", results[1], results[2])
+	}
+	if results[3].Error != bytecube.ErrTwistedCorner.Error() || results[3].Verified {
+		t.Error("Failed Solve of a twisted cube got: ", results[3])
+	}
+	if !strings.Contains(results[4].Error, "position 2") {
+		t.Error("Failed Solve of a bad scramble got: ", results[4])
+	}
+	if results[5].Error == "" || results[5].ID != "bad" || results[7].Error == "" || results[7].Line != 10 {
+		t.Error("Failed Solve of bad JSON got: ", results[5], results[7])
+	}
+	if results[6].Error != ErrStateAndScramble.Error() || results[6].Verified {
+		t.Error("Failed Solve of a state and a scramble got: ", results[6])
+	}
+	stop := errors.New("stop")
+	n := 0
+	err = Solve(context.Background(), items, Config{Solver: "twophase"}, func(r *Result) error {
+		n++
+		return stop
+	})
+	if err != stop || n != 1 {
+		t.Error("Failed Solve did not stop got: ", err, n)
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf)
+	w.Write(&Result{Line: 3, ID: "a,b", State: "x", Solution: "R U", Moves: 2, ElapsedMS: 1.5, Verified: true})
+	w.Write(&Result{Line: 4, Error: "bad"})
+	if err := w.Flush(); err != nil {
+		t.Fatal("Failed Flush got error: ", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil || len(rows) != 3 {
+		t.Fatal("Failed CSV got: ", rows, err)
+	}
+	if strings.Join(rows[0], "|") != strings.Join(CSV_HEADER, "|") {
+		t.Error("Failed CSV header got: ", rows[0])
+	}
+	if strings.Join(rows[1], "|") != "3|a,b|x|R U|2|1.500|true|" || rows[2][7] != "bad" {
+		t.Error("Failed CSV rows got: ", rows[1:])
+	}
+}
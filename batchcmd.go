@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/davidafox/rubikscubesolver/batch"
+	"github.com/davidafox/rubikscubesolver/solver"
+	"io"
+	"os"
+)
+
+//runBatch solves every cube in a file, it is run with: rubikscubesolver batch [-in file] [-out file] [-format jsonl|csv]
+func runBatch(args []string) int {
+	flags := flag.NewFlagSet("batch", flag.ExitOnError)
+	options := newSolverFlags(flags)
+	in := flags.String("in", "-", "the file of states to solve, - for stdin")
+	out := flags.String("out", "-", "the file to write the results to, - for stdout")
+	format := flags.String("format", "jsonl", "the format of the results: jsonl or csv")
+	parallel := flags.Int("parallel", 1, "the number of cubes solved at once")
+	timeout := flags.Duration("timeout", 0, "the longest a single solve may take, 0 for no limit")
+	flags.Parse(args)
+	if !solver.Registered(*options.name) {
+		fmt.Fprintln(os.Stderr, solver.ErrUnknownSolver, *options.name)
+		return EXIT_INVALID_INPUT
+	}
+	//the format is checked before -out is created so a mistake doesn't empty an existing file
+	newWriter, ok := map[string]func(io.Writer) batch.Writer{"jsonl": batch.NewJSONWriter, "csv": batch.NewCSVWriter}[*format]
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Unknown format:", *format)
+		return EXIT_INVALID_INPUT
+	}
+	var r io.Reader = os.Stdin
+	if *in != "-" {
+		f, err := os.Open(*in)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return EXIT_INVALID_INPUT
+		}
+		defer f.Close()
+		r = f
+	}
+	items, err := batch.Read(r)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return EXIT_INVALID_INPUT
+	}
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return EXIT_ERROR
+		}
+		defer f.Close()
+		w = f
+	}
+	results := newWriter(w)
+	config := batch.Config{Solver: *options.name, Options: options.options(), Parallel: *parallel, Timeout: *timeout}
+	code := EXIT_OK
+	if err := batch.Solve(context.Background(), items, config, results.Write); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		code = EXIT_ERROR
+	}
+	if err := results.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		code = EXIT_ERROR
+	}
+	return code
+}
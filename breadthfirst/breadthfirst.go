@@ -1,8 +1,9 @@
 package breadthfirst
 
 import (
-	"fmt"
+	"context"
 	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/solver"
 	"runtime"
 	"strconv"
 )
@@ -40,12 +41,28 @@ func NewFactory(size int) *Factory {
 	return f
 }
 
+func init() {
+	solver.Register("breadthfirst", func(ctx context.Context, c *bytecube.Cube, o solver.Options) (solver.Solver, error) {
+		//the cube is solved where its centers are rather than turned to the solved cube
+		target := o.Target
+		if target == "" {
+			target = c.SolvedState()
+		}
+		s, err := NewSolver(c.String(), target, 3, NewFactory(3))
+		if err != nil {
+			return nil, err
+		}
+		return solver.Legacy(s), nil
+	})
+}
+
 type Solver struct {
 	startingState bytecube.State
 	solvedState   bytecube.State
 	size          int
 	factory       CubeFactory
 	foundStates   []map[bytecube.State]string
+	observer      solver.Observer
 }
 
 type cubeState struct {
@@ -53,7 +70,19 @@ type cubeState struct {
 	steps string
 }
 
-func NewSolver(startingState, solved string, size int, factory CubeFactory) *Solver {
+//NewSolver returns a solver of the steps from startingState to target, or to bytecube.SOLVED when target is "".
+//Once turned into official face turns by solver.Legacy they reach target exactly, see solver.Relative.
+func NewSolver(startingState, target string, size int, factory CubeFactory) (*Solver, error) {
+	solved := bytecube.SOLVED
+	if target != "" {
+		relative, err := solver.RelativeState(startingState, target)
+		if err != nil {
+			return nil, err
+		}
+		startingState = relative
+		c, _ := bytecube.NewCube(relative)
+		solved = c.SolvedState()
+	}
 	s := new(Solver)
 	s.size = size
 	s.factory = factory
@@ -64,7 +93,7 @@ func NewSolver(startingState, solved string, size int, factory CubeFactory) *Sol
 	s.foundStates = make([]map[bytecube.State]string, 2, 2)
 	s.foundStates[0] = make(map[bytecube.State]string)
 	s.foundStates[1] = make(map[bytecube.State]string)
-	return s
+	return s, nil
 }
 
 func newCubeState(state bytecube.State, steps string) *cubeState {
@@ -74,7 +103,21 @@ func newCubeState(state bytecube.State, steps string) *cubeState {
 	return c
 }
 
+//SetObserver sets the observer that receives the progress of the solve, by default nothing is reported
+func (s *Solver) SetObserver(o solver.Observer) {
+	s.observer = o
+}
+
 func (s *Solver) Solve() string {
+	solution, _ := s.SolveContext(context.Background())
+	return solution
+}
+
+//SolveContext stops the search and returns a *solver.CancelError when ctx is canceled or its deadline passes
+func (s *Solver) SolveContext(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := solver.NewStopper(ctx)
 	states := make([][]*cubeState, 2, 2)
 	states[0] = make([]*cubeState, 1, 1)
 	states[1] = make([]*cubeState, 1, 1)
@@ -83,10 +126,14 @@ func (s *Solver) Solve() string {
 	s.foundStates[0][s.startingState] = ""
 	s.foundStates[1][s.solvedState] = ""
 	res := make(chan *results)
-	workers := s.spawnWorkers(res)
+	workers := s.spawnWorkers(ctx, res)
 	currentStates := make([][]*cubeState, 2, 2)
 	for i := 0; ; i++ {
 		for L := 0; L < 2; L++ {
+			if stop.Done() {
+				s.release()
+				return "", stop.Err()
+			}
 			currentStates[L] = currentStates[L][:0]
 			workersRunning := 0
 			jobsPerWorker := len(states[L]) / len(workers)
@@ -105,17 +152,23 @@ func (s *Solver) Solve() string {
 				}
 			}
 			for k := 0; k < workersRunning; k++ {
-				result := <-res
+				var result *results
+				select {
+				case result = <-res:
+				case <-ctx.Done():
+				}
+				if stop.Done() {
+					s.release()
+					return "", stop.Err()
+				}
 				if result.solved {
 					for _, x := range workers {
 						close(x)
 					}
-					return result.states[0].steps
+					return s.found(result.states[0].steps)
 				}
 				for _, x := range result.states {
 					if y, ok := s.foundStates[(L+1)%2][x.state]; ok {
-						fmt.Println(L)
-						fmt.Println(x.steps, y)
 						var solution string
 						if L == 0 {
 							solution = x.steps + y
@@ -125,7 +178,7 @@ func (s *Solver) Solve() string {
 						for _, x := range workers {
 							close(x)
 						}
-						return solution
+						return s.found(solution)
 					}
 					_, ok := s.foundStates[L][x.state]
 					if !ok {
@@ -134,18 +187,30 @@ func (s *Solver) Solve() string {
 					}
 				}
 			}
-			fmt.Println("On step ", i, "(", L, ")", "states: ", len(currentStates[L]))
 			states[L], currentStates[L] = currentStates[L], states[L]
+			s.observer.Notify(solver.Event{Kind: solver.LayerDone, Depth: i, Side: L, Frontier: [2]int{len(states[0]), len(states[1])}})
 		}
 	}
 }
 
-func (s *Solver) spawnWorkers(res chan *results) []chan *workerList {
+//found reports the solution to the observer, every step is a letter and a row
+func (s *Solver) found(solution string) (string, error) {
+	s.observer.Notify(solver.Event{Kind: solver.SolutionFound, Depth: len(solution) / 2, Solution: solution})
+	return solution, nil
+}
+
+//release empties the found states of both ends once the solve has stopped
+func (s *Solver) release() {
+	s.foundStates[0] = make(map[bytecube.State]string)
+	s.foundStates[1] = make(map[bytecube.State]string)
+}
+
+func (s *Solver) spawnWorkers(ctx context.Context, res chan *results) []chan *workerList {
 	channels := make([]chan *workerList, 0, 0)
 	for i := 0; i < runtime.GOMAXPROCS(-1); i++ {
 		c := make(chan *workerList)
 		channels = append(channels, c)
-		go s.NewWorker(res, c)
+		go s.NewWorker(ctx, res, c)
 	}
 	return channels
 }
@@ -167,24 +232,45 @@ type results struct {
 	solved bool
 }
 
-func (s *Solver) NewWorker(res chan *results, in chan *workerList) {
+//NewWorker expands the lists of states it receives until in is closed or ctx is done
+func (s *Solver) NewWorker(ctx context.Context, res chan *results, in chan *workerList) {
 	var nstates []*cubeState
 	var solved bool
-	for list := range in {
+	for {
+		var list *workerList
+		select {
+		case l, ok := <-in:
+			if !ok {
+				return
+			}
+			list = l
+		case <-ctx.Done():
+			return
+		}
 		resultStates := make([]*cubeState, 0)
-		for _, state := range list.states {
+		for i, state := range list.states {
+			if i%1024 == 0 && ctx.Err() != nil {
+				return
+			}
 			if list.side == STARTING_SIDE {
 				nstates, solved = s.getNextStates(state, true)
 			} else {
 				nstates, solved = s.getNextStatesFromSolved(state, true)
 			}
 			if solved {
-				res <- &results{nstates, solved}
+				select {
+				case res <- &results{nstates, solved}:
+				case <-ctx.Done():
+				}
 				return
 			}
 			resultStates = append(resultStates, nstates...)
 		}
-		res <- &results{resultStates, false}
+		select {
+		case res <- &results{resultStates, false}:
+		case <-ctx.Done():
+			return
+		}
 	}
 }
 
@@ -1,9 +1,12 @@
 package breadthfirst
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/davidafox/rubikscubesolver/bytecube"
 	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"github.com/davidafox/rubikscubesolver/solver"
 	"testing"
 )
 
@@ -19,7 +22,10 @@ func TestSolveRealCube(t *testing.T) {
 		c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
 		r := rubikscuberunner.NewRunner(c)
 		r.Run(x)
-		s := NewSolver(c.String(), "000000000111111111222222222333333333444444444555555555", 3, NewFactory(3))
+		s, err := NewSolver(c.String(), "", 3, NewFactory(3))
+		if err != nil {
+			t.Fatal(err)
+		}
 		result := s.Solve()
 		fmt.Println("Finished: ", i)
 		fmt.Println("Solution length: ", len(result)/2)
@@ -29,3 +35,19 @@ func TestSolveRealCube(t *testing.T) {
 		}
 	}
 }
+
+func TestSolveContext(t *testing.T) {
+	c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+	rubikscuberunner.NewRunner(c).Run("C1L0R1U0T1T0R1U0C1R0T0")
+	s, err := NewSolver(c.String(), "", 3, NewFactory(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := s.SolveContext(ctx)
+	var cancelErr *solver.CancelError
+	if !errors.As(err, &cancelErr) || !errors.Is(err, context.Canceled) {
+		t.Error("Failed SolveContext got: ", result, err, " expected: ", context.Canceled)
+	}
+}
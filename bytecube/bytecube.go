@@ -24,11 +24,18 @@ var indexValue = [...]uint32{7, 56, 448, 3584, 28672, 229376, 1835008, 14680064,
 
 const MAX uint32 = 4294967295
 
+//SOLVED is the state of a solved cube whose sides are the colors 0 to 5 in order
+const SOLVED = "000000000111111111222222222333333333444444444555555555"
+
 var ErrIncorrectNumber = errors.New("The cube has an incorrect number of cubie sides")
 var ErrCenterCubies = errors.New("Center cubies must be different colors")
 var ErrIncorrectColorNumbers = errors.New("There must be 9 of each color")
 var ErrIncorrectCorners = errors.New("The corner cubies are incorrect")
 var ErrIncorrectSides = errors.New("The side cubies are incorrect")
+var ErrTwistedCorner = errors.New("A corner cubie is twisted")
+var ErrFlippedSide = errors.New("A side cubie is flipped")
+var ErrSwappedCubies = errors.New("Two cubies are swapped")
+var ErrInvalidColor = errors.New("Colors must be the numbers 0 to 5")
 
 func (c *Cube) setLocation(side, spot, intValue int) {
 	if spot > 8 || spot < 0 {
@@ -92,6 +99,23 @@ func NewCube(s string) (*Cube, error) {
 	return c, nil
 }
 
+//NewValidCube reads a cube like NewCube but returns an error for anything other than a cube that can be solved
+func NewValidCube(s string) (*Cube, error) {
+	if len(s) != 54 {
+		return nil, ErrIncorrectNumber
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '5' {
+			return nil, ErrInvalidColor
+		}
+	}
+	c, _ := NewCube(s)
+	if _, err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
 func (c *Cube) SolvedState() string {
 	result := ""
 	for i := 0; i < 6; i++ {
@@ -115,6 +139,15 @@ func (c *Cube) Validate() (bool, error) {
 	if !checkSides(c) {
 		return false, ErrIncorrectSides
 	}
+	if !checkTwist(c) {
+		return false, ErrTwistedCorner
+	}
+	if !checkFlip(c) {
+		return false, ErrFlippedSide
+	}
+	if !checkParity(c) {
+		return false, ErrSwappedCubies
+	}
 	return true, nil
 }
 
@@ -163,7 +196,8 @@ func checkCorners(c *Cube) bool {
 			return false
 		}
 	}
-	return true
+	_, _, ok := getCornerCubies(c)
+	return ok
 }
 
 func getOpposites(c *Cube) map[int]int {
@@ -250,7 +284,8 @@ func checkSides(c *Cube) bool {
 			return false
 		}
 	}
-	return true
+	_, _, ok := getSideCubies(c)
+	return ok
 }
 
 func getSides(c *Cube) [][2]int {
@@ -258,7 +293,7 @@ func getSides(c *Cube) [][2]int {
 	result[0] = [2]int{c.getLocation(0, 1), c.getLocation(4, 7)}
 	result[1] = [2]int{c.getLocation(0, 5), c.getLocation(3, 3)}
 	result[2] = [2]int{c.getLocation(0, 7), c.getLocation(5, 1)}
-	result[3] = [2]int{c.getLocation(0, 3), c.getLocation(1, 6)}
+	result[3] = [2]int{c.getLocation(0, 3), c.getLocation(1, 5)}
 	result[4] = [2]int{c.getLocation(1, 1), c.getLocation(4, 3)}
 	result[5] = [2]int{c.getLocation(4, 5), c.getLocation(3, 1)}
 	result[6] = [2]int{c.getLocation(3, 7), c.getLocation(5, 5)}
@@ -293,6 +328,149 @@ func checkSideColors(sides [][2]int) bool {
 	return true
 }
 
+//CornerSpots holds the side and spot of the stickers of each corner position starting with the top or bottom sticker
+//and going clockwise. The positions are in the order of the cubie package, URF, UFL, ULB, UBR, DFR, DLF, DBL and DRB.
+var CornerSpots = [8][3][2]int{
+	{{4, 8}, {3, 0}, {0, 2}},
+	{{4, 6}, {0, 0}, {1, 2}},
+	{{4, 0}, {1, 0}, {2, 2}},
+	{{4, 2}, {2, 0}, {3, 2}},
+	{{5, 2}, {0, 8}, {3, 6}},
+	{{5, 0}, {1, 8}, {0, 6}},
+	{{5, 6}, {2, 8}, {1, 6}},
+	{{5, 8}, {3, 8}, {2, 6}},
+}
+
+//SideSpots holds the side and spot of the stickers of each side position starting with the top, bottom, front or back
+//sticker. The positions are in the order of the cubie package, UR, UF, UL, UB, DR, DF, DL, DB, FR, FL, BL and BR.
+var SideSpots = [12][2][2]int{
+	{{4, 5}, {3, 1}},
+	{{4, 7}, {0, 1}},
+	{{4, 3}, {1, 1}},
+	{{4, 1}, {2, 1}},
+	{{5, 5}, {3, 7}},
+	{{5, 1}, {0, 7}},
+	{{5, 3}, {1, 7}},
+	{{5, 7}, {2, 7}},
+	{{0, 5}, {3, 3}},
+	{{0, 3}, {1, 5}},
+	{{2, 5}, {1, 3}},
+	{{2, 3}, {3, 5}},
+}
+
+//getCornerCubies finds which corner cubie is in each position and how many times it is twisted clockwise.
+//It is not ok if a cubie's colors don't match any corner or a corner appears twice.
+func getCornerCubies(c *Cube) ([]int, []int, bool) {
+	cubies := make([]int, 8)
+	twists := make([]int, 8)
+	found := make([]bool, 8)
+	for i, spots := range CornerSpots {
+		colors := make([]int, 3)
+		for k, spot := range spots {
+			colors[k] = c.getLocation(spot[0], spot[1])
+		}
+		twist := 0
+		for twist < 3 && colors[twist] != c.getLocation(4, 4) && colors[twist] != c.getLocation(5, 4) {
+			twist++
+		}
+		if twist == 3 {
+			return nil, nil, false
+		}
+		cubies[i] = -1
+		for j, home := range CornerSpots {
+			if colors[(twist+1)%3] == c.getLocation(home[1][0], 4) && colors[(twist+2)%3] == c.getLocation(home[2][0], 4) {
+				cubies[i] = j
+			}
+		}
+		if cubies[i] == -1 || found[cubies[i]] {
+			return nil, nil, false
+		}
+		found[cubies[i]] = true
+		twists[i] = twist
+	}
+	return cubies, twists, true
+}
+
+//getSideCubies finds which side cubie is in each position and whether it is flipped.
+//It is not ok if a cubie's colors don't match any side or a side appears twice.
+func getSideCubies(c *Cube) ([]int, []int, bool) {
+	cubies := make([]int, 12)
+	flips := make([]int, 12)
+	found := make([]bool, 12)
+	for i, spots := range SideSpots {
+		a := c.getLocation(spots[0][0], spots[0][1])
+		b := c.getLocation(spots[1][0], spots[1][1])
+		cubies[i] = -1
+		for j, home := range SideSpots {
+			homeA := c.getLocation(home[0][0], 4)
+			homeB := c.getLocation(home[1][0], 4)
+			if a == homeA && b == homeB {
+				cubies[i] = j
+				flips[i] = 0
+			} else if a == homeB && b == homeA {
+				cubies[i] = j
+				flips[i] = 1
+			}
+		}
+		if cubies[i] == -1 || found[cubies[i]] {
+			return nil, nil, false
+		}
+		found[cubies[i]] = true
+	}
+	return cubies, flips, true
+}
+
+//checkTwist checks that the corner twists add up to a whole number of turns
+func checkTwist(c *Cube) bool {
+	_, twists, ok := getCornerCubies(c)
+	if !ok {
+		return false
+	}
+	sum := 0
+	for _, x := range twists {
+		sum += x
+	}
+	return sum%3 == 0
+}
+
+//checkFlip checks that an even number of side cubies are flipped
+func checkFlip(c *Cube) bool {
+	_, flips, ok := getSideCubies(c)
+	if !ok {
+		return false
+	}
+	sum := 0
+	for _, x := range flips {
+		sum += x
+	}
+	return sum%2 == 0
+}
+
+//checkParity checks that the corner and side permutations are both even or both odd since every quarter turn swaps both
+func checkParity(c *Cube) bool {
+	corners, _, ok := getCornerCubies(c)
+	if !ok {
+		return false
+	}
+	sides, _, ok := getSideCubies(c)
+	if !ok {
+		return false
+	}
+	return parity(corners) == parity(sides)
+}
+
+func parity(p []int) int {
+	swaps := 0
+	for i := 0; i < len(p); i++ {
+		for j := i + 1; j < len(p); j++ {
+			if p[i] > p[j] {
+				swaps++
+			}
+		}
+	}
+	return swaps % 2
+}
+
 func sideString(side uint32) string {
 	result := ""
 	for i := 0; i < 9; i++ {
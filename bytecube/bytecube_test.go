@@ -1,6 +1,7 @@
 package bytecube
 
 import (
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
 	"strconv"
 	"strings"
 	"testing"
@@ -798,3 +799,97 @@ func TestSolvedState(t *testing.T) {
 	}
 
 }
+
+func TestValidate(t *testing.T) {
+	scrambled, _ := NewCube(solvedCube)
+	scrambled.RotateR()
+	scrambled.RotateU()
+	scrambled.RotateFCounter()
+	scrambled.RotateL()
+	scrambled.RotateD()
+	scrambled.RotateBCounter()
+	scrambled.RotateR()
+	data := []struct {
+		state string
+		err   error
+	}{
+		{"000000000111111111222222222333333333444444444555555555", nil},
+		{scrambled.String(), nil},
+		{"444444444444444444444444444444444444444444444444444444", ErrCenterCubies},
+		{"000000001111111111222222222333333333444444444555555555", ErrIncorrectColorNumbers},
+		{"003000000111111111222222222433333333444444440555555555", ErrTwistedCorner},
+		{"040000000111111111222222222333333333444444404555555555", ErrFlippedSide},
+		{"030000000111111111222222222303333333444444444555555555", ErrSwappedCubies},
+	}
+	for _, x := range data {
+		c, _ := NewCube(x.state)
+		valid, err := c.Validate()
+		if err != x.err || valid != (x.err == nil) {
+			t.Error("Failed Validate got: ", valid, err, " expected: ", x.err, " c: ", c.String())
+		}
+	}
+}
+
+//getSides used to read the FL edge's left sticker from spot 6 of the left side, a corner, instead of spot 5.
+//Turning D moves that corner sticker but not the edge, so the valid cube after D failed with ErrIncorrectSides.
+func TestGetSides(t *testing.T) {
+	c, _ := NewCube(solvedCube)
+	c.RotateD()
+	if got := getSides(c)[3]; got != [2]int{0, 1} {
+		t.Error("Failed getSides FL edge got: ", got, " expected: [0 1]")
+	}
+	if _, err := c.Validate(); err != nil {
+		t.Error("Failed Validate after D got: ", err)
+	}
+}
+
+func TestNewValidCube(t *testing.T) {
+	data := []struct {
+		state string
+		err   error
+	}{
+		{"000000000111111111222222222333333333444444444555555555", nil},
+		{"00000000011111111122222222233333333344444444455555555", ErrIncorrectNumber},
+		{"000000000111111111222222222333333333444444444555555556", ErrInvalidColor},
+		{"00000000011111111122222222233333333344444444455555555x", ErrInvalidColor},
+		{"003000000111111111222222222433333333444444440555555555", ErrTwistedCorner},
+	}
+	for _, x := range data {
+		c, err := NewValidCube(x.state)
+		if err != x.err || (err == nil) != (c != nil) {
+			t.Error("Failed NewValidCube for ", x.state, " got: ", err, " expected: ", x.err)
+		}
+	}
+}
+
+func TestSymmetry(t *testing.T) {
+	moves := strings.Fields("R R' R2 L L' L2 U U' U2 D D' D2 F F' F2 B B' B2")
+	c, _ := NewCube(solvedCube)
+	rubikscuberunner.NewOfficialRunner(c).Run("R U' B' L F R' U2 F2 L' D R U L'")
+	state := c.State()
+	solved, _ := NewCube(solvedCube)
+	canonical, _ := state.Canonical()
+	for sym := 0; sym < N_SYMMETRY; sym++ {
+		if solved.State().Transform(sym) != solved.State() {
+			t.Error("Failed Transform ", sym, " of the solved cube")
+		}
+		if state.Transform(sym).Transform(InverseSymmetry(sym)) != state {
+			t.Error("Failed InverseSymmetry ", sym)
+		}
+		if c, _ := state.Transform(sym).Canonical(); c != canonical {
+			t.Error("Failed Canonical ", sym, " got: ", c, " expected: ", canonical)
+		}
+		for _, m := range moves {
+			moved := NewWithState(state)
+			rubikscuberunner.NewOfficialRunner(moved).Run(m)
+			transformed := NewWithState(state.Transform(sym))
+			rubikscuberunner.NewOfficialRunner(transformed).Run(TransformMove(sym, m))
+			if moved.State().Transform(sym) != transformed.State() {
+				t.Error("Failed TransformMove ", sym, " ", m, " got: ", TransformMove(sym, m))
+			}
+		}
+	}
+	if _, sym := state.Canonical(); state.Transform(sym) != canonical {
+		t.Error("Failed Canonical symmetry ", sym, " got: ", state.Transform(sym), " expected: ", canonical)
+	}
+}
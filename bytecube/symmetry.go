@@ -0,0 +1,206 @@
+package bytecube
+
+import "strings"
+
+//The 48 symmetries of the cube are the 24 whole cube rotations and the same rotations combined with a reflection.
+//Applying a symmetry moves every sticker to its new place and relabels the colors so the centers keep their colors,
+//so a state and its transformed states are solved by the same number of moves.
+
+//N_SYMMETRY is the number of symmetries, 0 is the identity
+const N_SYMMETRY = 48
+
+//sideLetters holds the official notation letter of each side
+const sideLetters = "FLBRUD"
+
+type symmetry struct {
+	//side and shift locate the sticker that is moved to each spot of each side
+	side  [6][9]uint8
+	shift [6][9]uint8
+	//face holds the side each side is moved to
+	face    [6]uint8
+	mirror  bool
+	inverse int
+}
+
+var symmetries [N_SYMMETRY]symmetry
+
+func init() {
+	var matrices [N_SYMMETRY][3][3]int
+	perms := [6][3]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}}
+	for i, p := range perms {
+		for signs := 0; signs < 8; signs++ {
+			var m [3][3]int
+			for row := 0; row < 3; row++ {
+				m[row][p[row]] = 1 - 2*(signs>>uint(row)&1)
+			}
+			matrices[i*8+signs] = m
+		}
+	}
+	positions := make(map[[3]int]int)
+	for side := 0; side < 6; side++ {
+		for spot := 0; spot < 9; spot++ {
+			positions[stickerPosition(side, spot)] = side*9 + spot
+		}
+	}
+	for sym, m := range matrices {
+		t := &symmetries[sym]
+		for side := 0; side < 6; side++ {
+			for spot := 0; spot < 9; spot++ {
+				//the sticker moved here comes from the position given by the inverse, the transpose
+				src := positions[multiply(transpose(m), stickerPosition(side, spot))]
+				t.side[side][spot] = uint8(src / 9)
+				t.shift[side][spot] = uint8(3 * (src % 9))
+			}
+			t.face[side] = uint8(positions[multiply(m, stickerPosition(side, 4))] / 9)
+		}
+		t.mirror = determinant(m) < 0
+		for inv, n := range matrices {
+			if n == transpose(m) {
+				t.inverse = inv
+			}
+		}
+	}
+}
+
+//stickerPosition returns the position of a sticker with the cube's center at 0 and its stickers 2 apart
+//The front is toward positive z, the right toward positive x and the top toward positive y.
+func stickerPosition(side, spot int) [3]int {
+	r, c := spot/3, spot%3
+	switch side {
+	case 0:
+		return [3]int{-2 + 2*c, 2 - 2*r, 3}
+	case 1:
+		return [3]int{-3, 2 - 2*r, -2 + 2*c}
+	case 2:
+		return [3]int{2 - 2*c, 2 - 2*r, -3}
+	case 3:
+		return [3]int{3, 2 - 2*r, 2 - 2*c}
+	case 4:
+		return [3]int{-2 + 2*c, 3, -2 + 2*r}
+	}
+	return [3]int{-2 + 2*c, -3, 2 - 2*r}
+}
+
+func multiply(m [3][3]int, p [3]int) [3]int {
+	var result [3]int
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			result[i] += m[i][j] * p[j]
+		}
+	}
+	return result
+}
+
+func transpose(m [3][3]int) [3][3]int {
+	var result [3][3]int
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			result[i][j] = m[j][i]
+		}
+	}
+	return result
+}
+
+func determinant(m [3][3]int) int {
+	return m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+}
+
+func (s State) sides() [6]uint32 {
+	return [6]uint32{s.zero, s.one, s.two, s.three, s.four, s.five}
+}
+
+func newState(sides [6]uint32) State {
+	return State{sides[0], sides[1], sides[2], sides[3], sides[4], sides[5]}
+}
+
+//stickers holds a state's sides with the center color of each side and the side with each center color
+type stickers struct {
+	sides  [6]uint32
+	colors [6]uint32
+	labels [8]uint8
+}
+
+func newStickers(s State) *stickers {
+	x := &stickers{sides: s.sides()}
+	for side := 0; side < 6; side++ {
+		x.colors[side] = (x.sides[side] >> 12) & 7
+		x.labels[x.colors[side]] = uint8(side)
+	}
+	return x
+}
+
+//sticker returns the new color of a spot, the color of the center the sticker's old center is moved to
+func (t *symmetry) sticker(x *stickers, side, spot int) uint32 {
+	v := (x.sides[t.side[side][spot]] >> t.shift[side][spot]) & 7
+	return x.colors[t.face[x.labels[v]]]
+}
+
+func (t *symmetry) transform(x *stickers) [6]uint32 {
+	var result [6]uint32
+	for side := 0; side < 6; side++ {
+		for spot := 0; spot < 9; spot++ {
+			result[side] |= t.sticker(x, side, spot) << (3 * uint32(spot))
+		}
+	}
+	return result
+}
+
+//Transform returns the state after applying the symmetry sym
+func (s State) Transform(sym int) State {
+	return newState(symmetries[sym].transform(newStickers(s)))
+}
+
+//Canonical returns the smallest of the 48 transformed states and the symmetry that gives it.
+//States with the same canonical state are solved by the same number of moves.
+func (s State) Canonical() (State, int) {
+	x := newStickers(s)
+	best := &symmetries[0]
+	bestSym := 0
+search:
+	for sym := 1; sym < N_SYMMETRY; sym++ {
+		t := &symmetries[sym]
+		//compare a sticker at a time from the most significant so most symmetries are rejected after a sticker or two
+		for side := 0; side < 6; side++ {
+			for spot := 8; spot >= 0; spot-- {
+				v, b := t.sticker(x, side, spot), best.sticker(x, side, spot)
+				if v > b {
+					continue search
+				}
+				if v < b {
+					best = t
+					bestSym = sym
+					continue search
+				}
+			}
+		}
+	}
+	return newState(best.transform(x)), bestSym
+}
+
+//InverseSymmetry returns the symmetry that undoes sym
+func InverseSymmetry(sym int) int {
+	return symmetries[sym].inverse
+}
+
+//TransformMove returns the move in official notation that does to a transformed state what move does to the state,
+//a reflection turns the other way. Anything other than a face turn is returned unchanged.
+func TransformMove(sym int, move string) string {
+	if move == "" {
+		return move
+	}
+	side := strings.IndexByte(sideLetters, move[0])
+	if side < 0 {
+		return move
+	}
+	t := &symmetries[sym]
+	result := string(sideLetters[t.face[side]])
+	switch {
+	case !t.mirror || move[1:] == "2":
+		return result + move[1:]
+	case move[1:] == "'":
+		return result
+	}
+	return result + "'"
+}
@@ -1,9 +1,12 @@
 package combined
 
 import (
-	"fmt"
+	"context"
+	"github.com/davidafox/rubikscubesolver/algorithm"
 	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/solver"
 	"runtime"
+	"strings"
 )
 
 type Cube interface {
@@ -31,6 +34,10 @@ type rotations struct {
 const STARTING_SIDE = 0
 const SOLVED_SIDE = 1
 
+//STATE_BYTES estimates the memory of one found state without its steps:
+//its map entry with the spare room of the map, and its cubeState and pointer on the frontier
+const STATE_BYTES = 160
+
 type CubeFactory interface {
 	New(bytecube.State) Cube
 }
@@ -47,27 +54,61 @@ func NewFactory() *Factory {
 	return f
 }
 
+//DEFAULT_DEPTH is the breadth-first depth used through the solver registry when none is given
+const DEFAULT_DEPTH = 6
+
+func init() {
+	solver.Register("combined", func(ctx context.Context, c *bytecube.Cube, o solver.Options) (solver.Solver, error) {
+		if o.Depth == 0 {
+			o.Depth = DEFAULT_DEPTH
+		}
+		s, err := NewSolver(c.String(), o.Target, NewFactory(), o.Depth)
+		if err != nil {
+			return nil, err
+		}
+		s.SetMemoryBudget(o.MemoryBudget)
+		return s, nil
+	})
+}
+
 type Solver struct {
 	startingState bytecube.State
 	solvedState   bytecube.State
 	factory       CubeFactory
-	foundStates   []map[bytecube.State]string
-	rotations     []rotations
-	depth         int
-}
-
+	//foundStates is keyed on canonical states so each state is stored once for all its symmetries.
+	//The starting side holds the steps from the starting state to the first state found with the key,
+	//the solved side holds the steps that solve the key itself.
+	foundStates []map[bytecube.State]string
+	rotations   []rotations
+	depth       int
+	stop        *solver.Stopper
+	observer    solver.Observer
+	budget      int
+	used        int
+	reached     [2]int
+}
+
+//key is the canonical state of state and sym the symmetry that gives it
 type cubeState struct {
 	state bytecube.State
 	steps string
+	key   bytecube.State
+	sym   int
 }
 
-func NewSolver(startingState string, factory CubeFactory, depth int) *Solver {
+//NewSolver returns a solver of the moves from startingState to target, or to the solved cube when target is "".
+//It returns an error when startingState is not 54 stickers or target can't be reached, see solver.Relative.
+func NewSolver(startingState, target string, factory CubeFactory, depth int) (*Solver, error) {
 	s := new(Solver)
 	s.factory = factory
 	c, err := bytecube.NewCube(startingState)
 	if err != nil {
-		fmt.Println("Invalid State in solver")
-		return nil
+		return nil, err
+	}
+	if target != "" {
+		if c, err = solver.Relative(c, target); err != nil {
+			return nil, err
+		}
 	}
 	s.startingState = c.State()
 	solvedStateCube, _ := bytecube.NewCube(c.SolvedState())
@@ -96,7 +137,8 @@ func NewSolver(startingState string, factory CubeFactory, depth int) *Solver {
 		{doubleBack, "B2"},
 	}
 	s.depth = depth
-	return s
+	s.stop = solver.NewStopper(context.Background())
+	return s, nil
 }
 
 func newCubeState(state bytecube.State, steps string) *cubeState {
@@ -106,19 +148,68 @@ func newCubeState(state bytecube.State, steps string) *cubeState {
 	return c
 }
 
+//SetObserver sets the observer that receives the progress of the solve, by default nothing is reported
+func (s *Solver) SetObserver(o solver.Observer) {
+	s.observer = o
+}
+
+//SetMemoryBudget limits the memory in bytes the breadth-first search may use for its states, 0 means no limit.
+//A side stops expanding when its next layer could go over the budget and the depth-first search is then used for the remaining depth.
+func (s *Solver) SetMemoryBudget(bytes int) {
+	s.budget = bytes
+}
+
+//Depths returns the number of breadth-first layers each side reached in the last solve
+func (s *Solver) Depths() [2]int {
+	return s.reached
+}
+
+//bytesPerState estimates the memory of a found state whose steps are depth moves long
+func bytesPerState(depth int) int {
+	//a move takes up to 3 bytes and strings are allocated in multiples of 8 bytes
+	return STATE_BYTES + (depth*3+7)/8*8
+}
+
 func (s *Solver) Solve() string {
+	solution, _ := s.SolveContext(context.Background())
+	return solution
+}
+
+//SolveContext stops the search and returns a *solver.CancelError when ctx is canceled or its deadline passes
+func (s *Solver) SolveContext(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.stop = solver.NewStopper(ctx)
+	s.used = 0
+	s.reached = [2]int{}
+	stopped := [2]bool{}
 	states := make([][]*cubeState, 2, 2)
 	states[0] = make([]*cubeState, 1, 1)
 	states[1] = make([]*cubeState, 1, 1)
 	states[0][0] = newCubeState(s.startingState, "")
 	states[1][0] = newCubeState(s.solvedState, "")
-	s.foundStates[0][s.startingState] = ""
-	s.foundStates[1][s.solvedState] = ""
+	startingKey, _ := s.startingState.Canonical()
+	solvedKey, _ := s.solvedState.Canonical()
+	s.foundStates[0][startingKey] = ""
+	s.foundStates[1][solvedKey] = ""
 	res := make(chan *results)
-	workers := s.spawnWorkers(res)
+	workers := s.spawnWorkers(ctx, res)
 	currentStates := make([][]*cubeState, 2, 2)
-	for i := 0; i < s.depth; i++ {
+	for i := 0; i < s.depth && !(stopped[0] && stopped[1]); i++ {
 		for L := 0; L < 2; L++ {
+			if stopped[L] {
+				continue
+			}
+			if s.stop.Done() {
+				s.release()
+				return "", s.stop.Err()
+			}
+			//every state can give a new state for each rotation before duplicates are removed
+			if s.budget > 0 && s.used+len(states[L])*len(s.rotations)*bytesPerState(i+1) > s.budget {
+				stopped[L] = true
+				s.observer.Notify(solver.Event{Kind: solver.ExpansionStopped, Depth: i, Side: L, Frontier: [2]int{len(states[0]), len(states[1])}})
+				continue
+			}
 			currentStates[L] = currentStates[L][:0]
 			workersRunning := 0
 			jobsPerWorker := len(states[L]) / len(workers)
@@ -137,47 +228,112 @@ func (s *Solver) Solve() string {
 				}
 			}
 			for k := 0; k < workersRunning; k++ {
-				result := <-res
+				var result *results
+				select {
+				case result = <-res:
+				case <-ctx.Done():
+				}
+				if s.stop.Done() {
+					s.release()
+					return "", s.stop.Err()
+				}
 				if result.solved {
 					for _, x := range workers {
 						close(x)
 					}
-					return result.states[0].steps
+					return s.found(result.states[0].steps)
 				}
 				for _, x := range result.states {
-					if y, ok := s.foundStates[(L+1)%2][x.state]; ok {
+					if y, ok := s.foundStates[(L+1)%2][x.key]; ok {
 						var solution string
 						if L == 0 {
-							solution = x.steps + " " + y
+							solution = s.meet(x.steps, y)
 						} else {
-							solution = y + " " + x.steps
+							solution = s.meet(y, transformSteps(x.sym, x.steps))
 						}
 						for _, x := range workers {
 							close(x)
 						}
-						return solution
+						return s.found(solution)
 					}
-					_, ok := s.foundStates[L][x.state]
+					_, ok := s.foundStates[L][x.key]
 					if !ok {
 						currentStates[L] = append(currentStates[L], x)
-						s.foundStates[L][x.state] = x.steps
+						if L == STARTING_SIDE {
+							s.foundStates[L][x.key] = x.steps
+						} else {
+							s.foundStates[L][x.key] = transformSteps(x.sym, x.steps)
+						}
 					}
 				}
 			}
-			fmt.Println("On step ", i, "(", L, ")", "states: ", len(currentStates[L]))
 			states[L], currentStates[L] = currentStates[L], states[L]
+			s.used += len(states[L]) * bytesPerState(i+1)
+			s.reached[L] = i + 1
+			s.observer.Notify(solver.Event{Kind: solver.LayerDone, Depth: i, Side: L, Frontier: [2]int{len(states[0]), len(states[1])}})
 		}
 	}
-	for i := 1; i <= 20-(s.depth*2); i++ {
-		fmt.Println("Depth: ", i)
+	for _, x := range workers {
+		close(x)
+	}
+	for i := 1; i <= 20-s.reached[0]-s.reached[1]; i++ {
+		s.observer.Notify(solver.Event{Kind: solver.DepthStarted, Depth: i})
 		for _, state := range states[0] {
 			result, depth := s.SolveR(state.state, 0, i, state.steps)
+			if s.stop.Err() != nil {
+				s.release()
+				return "", s.stop.Err()
+			}
 			if depth > 0 {
-				return result
+				return s.found(result)
 			}
 		}
 	}
-	return ""
+	return "", nil
+}
+
+//meet joins the steps reaching a state from the starting state with the steps solving the state's canonical state
+func (s *Solver) meet(steps, canonicalSteps string) string {
+	_, sym := s.apply(s.startingState, steps).Canonical()
+	return steps + " " + transformSteps(bytecube.InverseSymmetry(sym), canonicalSteps)
+}
+
+//apply returns the state reached from state by steps
+func (s *Solver) apply(state bytecube.State, steps string) bytecube.State {
+	c := s.factory.New(state)
+	for _, step := range strings.Fields(steps) {
+		for _, x := range s.rotations {
+			if x.letter == step {
+				x.fun(c)
+			}
+		}
+	}
+	return c.State()
+}
+
+//transformSteps returns the steps that do to the state transformed by sym what steps do to the state
+func transformSteps(sym int, steps string) string {
+	fields := strings.Fields(steps)
+	for i, x := range fields {
+		fields[i] = bytecube.TransformMove(sym, x)
+	}
+	return strings.Join(fields, " ")
+}
+
+//found simplifies the solution, where the two searches meet moves can often be merged, and reports it to the observer
+func (s *Solver) found(solution string) (string, error) {
+	solution, err := algorithm.SimplifyString(solution)
+	if err != nil {
+		return "", err
+	}
+	s.observer.Notify(solver.Event{Kind: solver.SolutionFound, Depth: len(strings.Fields(solution)), Solution: solution})
+	return solution, nil
+}
+
+//release drops the states both sides found so a stopped solver doesn't hold on to them
+func (s *Solver) release() {
+	s.foundStates[0] = make(map[bytecube.State]string)
+	s.foundStates[1] = make(map[bytecube.State]string)
 }
 
 type action struct {
@@ -442,21 +598,21 @@ func (s *Solver) solveRFromDoubleBack(state bytecube.State, depth, maxDepth int,
 }
 
 func (s *Solver) genericSolveR(actions []action, state bytecube.State, depth, maxDepth int, steps string) (string, int) {
-	if depth >= maxDepth {
+	if depth >= maxDepth || s.stop.Stopped() {
 		return "", -1
 	}
 	for i := 0; i < len(actions); i++ {
 		cube := s.factory.New(state)
 		rState, rSolved := actions[i].fun(cube)
-		if _, ok := s.foundStates[1][rState]; ok {
-			fmt.Println("Found Solution, depth: ", depth+1)
-			return steps + " " + actions[i].letter + " " + s.foundStates[1][rState], depth + 1
+		key, sym := rState.Canonical()
+		if y, ok := s.foundStates[1][key]; ok {
+			return steps + " " + actions[i].letter + " " + transformSteps(bytecube.InverseSymmetry(sym), y), depth + 1
 		}
-		if _, ok := s.foundStates[0][rState]; ok {
-			return "", -1
+		if _, ok := s.foundStates[0][key]; ok {
+			//the breadth-first search already reached this state or one of its symmetries, only skip this move
+			continue
 		}
 		if rSolved {
-			fmt.Println("Found Solution not in map, depth: ", depth+1)
 			return steps + " " + actions[i].letter, depth
 		}
 		rSteps, rDepth := actions[i].callback(rState, depth+1, maxDepth, steps+" "+actions[i].letter)
@@ -467,12 +623,12 @@ func (s *Solver) genericSolveR(actions []action, state bytecube.State, depth, ma
 	return "", -1
 }
 
-func (s *Solver) spawnWorkers(res chan *results) []chan *workerList {
+func (s *Solver) spawnWorkers(ctx context.Context, res chan *results) []chan *workerList {
 	channels := make([]chan *workerList, 0, 0)
 	for i := 0; i < runtime.GOMAXPROCS(-1); i++ {
 		c := make(chan *workerList)
 		channels = append(channels, c)
-		go s.NewWorker(res, c)
+		go s.NewWorker(ctx, res, c)
 	}
 	return channels
 }
@@ -494,24 +650,48 @@ type results struct {
 	solved bool
 }
 
-func (s *Solver) NewWorker(res chan *results, in chan *workerList) {
+//NewWorker expands the lists of states it receives until in is closed or ctx is done
+func (s *Solver) NewWorker(ctx context.Context, res chan *results, in chan *workerList) {
 	var nstates []*cubeState
 	var solved bool
-	for list := range in {
+	for {
+		var list *workerList
+		select {
+		case l, ok := <-in:
+			if !ok {
+				return
+			}
+			list = l
+		case <-ctx.Done():
+			return
+		}
 		resultStates := make([]*cubeState, 0)
-		for _, state := range list.states {
+		for i, state := range list.states {
+			if i%1024 == 0 && ctx.Err() != nil {
+				return
+			}
 			if list.side == STARTING_SIDE {
 				nstates, solved = s.getNextStates(state, true)
 			} else {
 				nstates, solved = s.getNextStatesFromSolved(state, true)
 			}
 			if solved {
-				res <- &results{nstates, solved}
+				select {
+				case res <- &results{nstates, solved}:
+				case <-ctx.Done():
+				}
 				return
 			}
+			for _, x := range nstates {
+				x.key, x.sym = x.state.Canonical()
+			}
 			resultStates = append(resultStates, nstates...)
 		}
-		res <- &results{resultStates, false}
+		select {
+		case res <- &results{resultStates, false}:
+		case <-ctx.Done():
+			return
+		}
 	}
 }
 
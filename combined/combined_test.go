@@ -1,10 +1,14 @@
 package combined
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/davidafox/rubikscubesolver/bytecube"
 	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"github.com/davidafox/rubikscubesolver/solver"
 	"testing"
+	"time"
 )
 
 func TestSolveRealCube(t *testing.T) {
@@ -19,7 +23,10 @@ func TestSolveRealCube(t *testing.T) {
 		c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
 		r := rubikscuberunner.NewOfficialRunner(c)
 		r.Run(x)
-		s := NewSolver(c.String(), NewFactory(), 2)
+		s, err := NewSolver(c.String(), "", NewFactory(), 2)
+		if err != nil {
+			t.Fatal(err)
+		}
 		result := s.Solve()
 		fmt.Println("Finished: ", i)
 		fmt.Println("Solution:", result)
@@ -30,3 +37,133 @@ func TestSolveRealCube(t *testing.T) {
 		}
 	}
 }
+
+func TestSolveContext(t *testing.T) {
+	c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+	rubikscuberunner.NewOfficialRunner(c).Run("R U' B' L F R' U2 F2 L' D R U L'")
+	s, err := NewSolver(c.String(), "", NewFactory(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	result, err := s.SolveContext(ctx)
+	var cancelErr *solver.CancelError
+	if !errors.As(err, &cancelErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("Failed SolveContext got: ", result, err, " expected: ", context.DeadlineExceeded)
+	}
+}
+
+func TestObserver(t *testing.T) {
+	c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+	rubikscuberunner.NewOfficialRunner(c).Run("R U L F")
+	s, err := NewSolver(c.String(), "", NewFactory(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []solver.Event
+	s.SetObserver(func(e solver.Event) {
+		events = append(events, e)
+	})
+	result := s.Solve()
+	if len(events) == 0 {
+		t.Fatal("Failed Observer got no events")
+	}
+	last := events[len(events)-1]
+	if last.Kind != solver.SolutionFound || last.Solution != result || last.Depth != 4 {
+		t.Error("Failed Observer got: ", last, " expected solution: ", result)
+	}
+	if events[0].Kind != solver.LayerDone || events[0].Frontier != [2]int{18, 1} {
+		t.Error("Failed Observer got: ", events[0], " expected the first layer with 18 states")
+	}
+}
+
+func TestMemoryBudget(t *testing.T) {
+	c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+	r := rubikscuberunner.NewOfficialRunner(c)
+	r.Run("R U L F")
+	s, err := NewSolver(c.String(), "", NewFactory(), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//enough for the first layer of the starting side only
+	s.SetMemoryBudget(18 * bytesPerState(1))
+	var stopped []solver.Event
+	s.SetObserver(func(e solver.Event) {
+		if e.Kind == solver.ExpansionStopped {
+			stopped = append(stopped, e)
+		}
+	})
+	result := s.Solve()
+	if s.Depths() != [2]int{1, 0} {
+		t.Error("Failed MemoryBudget got depths: ", s.Depths(), " expected: ", [2]int{1, 0})
+	}
+	if len(stopped) != 2 || stopped[0].Side != 1 || stopped[0].Depth != 0 || stopped[1].Side != 0 || stopped[1].Depth != 1 {
+		t.Error("Failed MemoryBudget got events: ", stopped)
+	}
+	r.Run(result)
+	if !c.Solved() {
+		t.Error("Failed MemoryBudget to solve with ", result, " got: ", c.String())
+	}
+}
+
+func TestSymmetryReduction(t *testing.T) {
+	c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+	r := rubikscuberunner.NewOfficialRunner(c)
+	r.Run("L' U2 B R' D F2")
+	s, err := NewSolver(c.String(), "", NewFactory(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var frontiers [][2]int
+	s.SetObserver(func(e solver.Event) {
+		if e.Kind == solver.LayerDone && e.Side == SOLVED_SIDE {
+			frontiers = append(frontiers, e.Frontier)
+		}
+	})
+	result := s.Solve()
+	//every quarter turn of the solved cube is the same state under a symmetry and so is every half turn
+	if len(frontiers) == 0 || frontiers[0][SOLVED_SIDE] != 2 {
+		t.Error("Failed SymmetryReduction got frontiers: ", frontiers)
+	}
+	r.Run(result)
+	if !c.Solved() {
+		t.Error("Failed SymmetryReduction to solve with ", result, " got: ", c.String())
+	}
+}
+
+//A move reaching a state the breadth-first search already found, or one of its symmetries, is skipped but the other
+//moves from the same state are still searched. Giving up on the whole state instead missed solutions once states are
+//stored by symmetry, since far more moves then lead to a state that has been found.
+func TestSolveRSkipsFoundStates(t *testing.T) {
+	c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+	s, err := NewSolver(c.String(), "", NewFactory(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	solvedKey, _ := c.State().Canonical()
+	s.foundStates[1][solvedKey] = ""
+	//U solves the cube but R, the first move tried, reaches a state marked as found
+	rubikscuberunner.NewOfficialRunner(c).Run("U'")
+	start := c.State()
+	rubikscuberunner.NewOfficialRunner(c).Run("R")
+	foundKey, _ := c.State().Canonical()
+	s.foundStates[0][foundKey] = "R"
+	steps, depth := s.SolveR(start, 0, 1, "")
+	if depth == -1 {
+		t.Fatal("Failed SolveR gave up after a found state")
+	}
+	solved, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+	r := rubikscuberunner.NewOfficialRunner(solved)
+	r.Run("U'")
+	r.Run(steps)
+	if !solved.Solved() {
+		t.Error("Failed SolveR got: ", steps)
+	}
+}
+
+func TestNewSolverInvalidState(t *testing.T) {
+	if s, err := NewSolver("0000", "", NewFactory(), 2); s != nil || err != bytecube.ErrIncorrectNumber {
+		t.Error("Failed NewSolver of a short state got: ", err, " expected: ", bytecube.ErrIncorrectNumber)
+	}
+}
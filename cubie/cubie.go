@@ -0,0 +1,472 @@
+package cubie
+
+import (
+	"errors"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"strconv"
+)
+
+//cubie describes a cube by the piece in each of the 8 corner and 12 edge
+//positions and how each piece is turned, instead of by the sticker colors.
+//A corner's orientation is the number of clockwise twists its U or D sticker
+//is away from the U or D face, an edge's orientation is 1 when it is flipped.
+
+var ErrInvalidCube = errors.New("The cube's stickers do not describe a real cube")
+var ErrTwistedCorner = errors.New("A corner is twisted")
+var ErrFlippedEdge = errors.New("An edge is flipped")
+var ErrParity = errors.New("Two pieces are swapped")
+
+//corner positions
+const (
+	URF = iota
+	UFL
+	ULB
+	UBR
+	DFR
+	DLF
+	DBL
+	DRB
+)
+
+//edge positions
+const (
+	UR = iota
+	UF
+	UL
+	UB
+	DR
+	DF
+	DL
+	DB
+	FR
+	FL
+	BL
+	BR
+)
+
+const (
+	faceU = iota
+	faceR
+	faceF
+	faceD
+	faceL
+	faceB
+)
+
+//bytecube side for each face
+var faceSide = [6]int{4, 3, 0, 5, 1, 2}
+
+var faceLetter = [6]string{"U", "R", "F", "D", "L", "B"}
+
+//spotIndex returns the index in a bytecube state of a side and spot from bytecube.CornerSpots or bytecube.SideSpots
+func spotIndex(spot [2]int) int {
+	return spot[0]*9 + spot[1]
+}
+
+var cornerFace = [8][3]int{
+	{faceU, faceR, faceF},
+	{faceU, faceF, faceL},
+	{faceU, faceL, faceB},
+	{faceU, faceB, faceR},
+	{faceD, faceF, faceR},
+	{faceD, faceL, faceF},
+	{faceD, faceB, faceL},
+	{faceD, faceR, faceB},
+}
+
+var edgeFace = [12][2]int{
+	{faceU, faceR},
+	{faceU, faceF},
+	{faceU, faceL},
+	{faceU, faceB},
+	{faceD, faceR},
+	{faceD, faceF},
+	{faceD, faceL},
+	{faceD, faceB},
+	{faceF, faceR},
+	{faceF, faceL},
+	{faceB, faceL},
+	{faceB, faceR},
+}
+
+//Cube holds the piece in each position and its orientation.
+//Colors is the color of the center of each side in bytecube's side order and is only used when converting to and from stickers.
+type Cube struct {
+	CornerPerm   [8]int
+	CornerOrient [8]int
+	EdgePerm     [12]int
+	EdgeOrient   [12]int
+	Colors       [6]int
+}
+
+//the six clockwise quarter turns of the faces
+var basicMoves = [6]Cube{
+	{
+		CornerPerm: [8]int{UBR, URF, UFL, ULB, DFR, DLF, DBL, DRB},
+		EdgePerm:   [12]int{UB, UR, UF, UL, DR, DF, DL, DB, FR, FL, BL, BR},
+	},
+	{
+		CornerPerm:   [8]int{DFR, UFL, ULB, URF, DRB, DLF, DBL, UBR},
+		CornerOrient: [8]int{2, 0, 0, 1, 1, 0, 0, 2},
+		EdgePerm:     [12]int{FR, UF, UL, UB, BR, DF, DL, DB, DR, FL, BL, UR},
+	},
+	{
+		CornerPerm:   [8]int{UFL, DLF, ULB, UBR, URF, DFR, DBL, DRB},
+		CornerOrient: [8]int{1, 2, 0, 0, 2, 1, 0, 0},
+		EdgePerm:     [12]int{UR, FL, UL, UB, DR, FR, DL, DB, UF, DF, BL, BR},
+		EdgeOrient:   [12]int{0, 1, 0, 0, 0, 1, 0, 0, 1, 1, 0, 0},
+	},
+	{
+		CornerPerm: [8]int{URF, UFL, ULB, UBR, DLF, DBL, DRB, DFR},
+		EdgePerm:   [12]int{UR, UF, UL, UB, DF, DL, DB, DR, FR, FL, BL, BR},
+	},
+	{
+		CornerPerm:   [8]int{URF, ULB, DBL, UBR, DFR, UFL, DLF, DRB},
+		CornerOrient: [8]int{0, 1, 2, 0, 0, 2, 1, 0},
+		EdgePerm:     [12]int{UR, UF, BL, UB, DR, DF, FL, DB, FR, UL, DL, BR},
+	},
+	{
+		CornerPerm:   [8]int{URF, UFL, UBR, DRB, DFR, DLF, ULB, DBL},
+		CornerOrient: [8]int{0, 0, 1, 2, 0, 0, 2, 1},
+		EdgePerm:     [12]int{UR, UF, UL, BR, DR, DF, DL, BL, FR, FL, UB, DB},
+		EdgeOrient:   [12]int{0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 1, 1},
+	},
+}
+
+const N_MOVE = 18
+
+//the 18 face turns, move m turns face m/3 clockwise m%3+1 times
+var moveCube [N_MOVE]Cube
+
+func init() {
+	for f := 0; f < 6; f++ {
+		c := NewCube()
+		for p := 0; p < 3; p++ {
+			c.Multiply(&basicMoves[f])
+			moveCube[3*f+p] = *c
+		}
+	}
+}
+
+//NewCube returns a solved cube
+func NewCube() *Cube {
+	c := new(Cube)
+	for i := range c.CornerPerm {
+		c.CornerPerm[i] = i
+	}
+	for i := range c.EdgePerm {
+		c.EdgePerm[i] = i
+	}
+	for i := range c.Colors {
+		c.Colors[i] = i
+	}
+	return c
+}
+
+//NewMoveCube returns the cube reached by doing move m to a solved cube
+func NewMoveCube(m int) *Cube {
+	c := NewCube()
+	c.Move(m)
+	return c
+}
+
+//MoveName returns move m in official notation
+func MoveName(m int) string {
+	return faceLetter[m/3] + [...]string{"", "2", "'"}[m%3]
+}
+
+//Multiply sets c to the result of doing b after c
+func (c *Cube) Multiply(b *Cube) {
+	var cp, co [8]int
+	var ep, eo [12]int
+	for i := 0; i < 8; i++ {
+		cp[i] = c.CornerPerm[b.CornerPerm[i]]
+		co[i] = (c.CornerOrient[b.CornerPerm[i]] + b.CornerOrient[i]) % 3
+	}
+	for i := 0; i < 12; i++ {
+		ep[i] = c.EdgePerm[b.EdgePerm[i]]
+		eo[i] = (c.EdgeOrient[b.EdgePerm[i]] + b.EdgeOrient[i]) % 2
+	}
+	c.CornerPerm, c.CornerOrient, c.EdgePerm, c.EdgeOrient = cp, co, ep, eo
+}
+
+//Move does move m to the cube
+func (c *Cube) Move(m int) {
+	c.Multiply(&moveCube[m])
+}
+
+//Inverse returns the cube that undoes c
+func (c *Cube) Inverse() *Cube {
+	r := new(Cube)
+	r.Colors = c.Colors
+	for i := 0; i < 8; i++ {
+		r.CornerPerm[c.CornerPerm[i]] = i
+	}
+	for i := 0; i < 8; i++ {
+		r.CornerOrient[i] = (3 - c.CornerOrient[r.CornerPerm[i]]) % 3
+	}
+	for i := 0; i < 12; i++ {
+		r.EdgePerm[c.EdgePerm[i]] = i
+	}
+	for i := 0; i < 12; i++ {
+		r.EdgeOrient[i] = c.EdgeOrient[r.EdgePerm[i]]
+	}
+	return r
+}
+
+//Solved is true when every piece is in its own position and oriented
+func (c *Cube) Solved() bool {
+	for i := 0; i < 8; i++ {
+		if c.CornerPerm[i] != i || c.CornerOrient[i] != 0 {
+			return false
+		}
+	}
+	for i := 0; i < 12; i++ {
+		if c.EdgePerm[i] != i || c.EdgeOrient[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+//FromBytecube reads the pieces from the stickers of b using the center colors to find which face each sticker belongs to
+func FromBytecube(b *bytecube.Cube) (*Cube, error) {
+	s := b.String()
+	c := new(Cube)
+	colorFace := make(map[byte]int)
+	for face, side := range faceSide {
+		colorFace[s[side*9+4]] = face
+		c.Colors[side] = int(s[side*9+4] - '0')
+	}
+	if len(colorFace) != 6 {
+		return nil, ErrInvalidCube
+	}
+	face := func(spot [2]int) int {
+		x, ok := colorFace[s[spotIndex(spot)]]
+		if !ok {
+			return -1
+		}
+		return x
+	}
+	for i := 0; i < 8; i++ {
+		ori := 0
+		for ori = 0; ori < 3; ori++ {
+			f := face(bytecube.CornerSpots[i][ori])
+			if f == faceU || f == faceD {
+				break
+			}
+		}
+		if ori == 3 {
+			return nil, ErrInvalidCube
+		}
+		col1 := face(bytecube.CornerSpots[i][(ori+1)%3])
+		col2 := face(bytecube.CornerSpots[i][(ori+2)%3])
+		found := false
+		for j := 0; j < 8; j++ {
+			if col1 == cornerFace[j][1] && col2 == cornerFace[j][2] {
+				c.CornerPerm[i] = j
+				c.CornerOrient[i] = ori
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, ErrInvalidCube
+		}
+	}
+	for i := 0; i < 12; i++ {
+		a := face(bytecube.SideSpots[i][0])
+		b := face(bytecube.SideSpots[i][1])
+		found := false
+		for j := 0; j < 12; j++ {
+			if a == edgeFace[j][0] && b == edgeFace[j][1] {
+				c.EdgePerm[i] = j
+				c.EdgeOrient[i] = 0
+				found = true
+				break
+			}
+			if a == edgeFace[j][1] && b == edgeFace[j][0] {
+				c.EdgePerm[i] = j
+				c.EdgeOrient[i] = 1
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, ErrInvalidCube
+		}
+	}
+	if !c.piecesPresent() {
+		return nil, ErrInvalidCube
+	}
+	return c, nil
+}
+
+//Bytecube returns the stickers of the cube using Colors for each face
+func (c *Cube) Bytecube() *bytecube.Cube {
+	var f [54]int
+	color := func(face int) int {
+		return c.Colors[faceSide[face]]
+	}
+	for side := 0; side < 6; side++ {
+		f[side*9+4] = c.Colors[side]
+	}
+	for i := 0; i < 8; i++ {
+		j := c.CornerPerm[i]
+		ori := c.CornerOrient[i]
+		for n := 0; n < 3; n++ {
+			f[spotIndex(bytecube.CornerSpots[i][(n+ori)%3])] = color(cornerFace[j][n])
+		}
+	}
+	for i := 0; i < 12; i++ {
+		j := c.EdgePerm[i]
+		ori := c.EdgeOrient[i]
+		for n := 0; n < 2; n++ {
+			f[spotIndex(bytecube.SideSpots[i][(n+ori)%2])] = color(edgeFace[j][n])
+		}
+	}
+	s := ""
+	for _, x := range f {
+		s += strconv.Itoa(x)
+	}
+	b, _ := bytecube.NewCube(s)
+	return b
+}
+
+func (c *Cube) piecesPresent() bool {
+	var corners [8]bool
+	var edges [12]bool
+	for _, x := range c.CornerPerm {
+		corners[x] = true
+	}
+	for _, x := range c.EdgePerm {
+		edges[x] = true
+	}
+	for _, x := range corners {
+		if !x {
+			return false
+		}
+	}
+	for _, x := range edges {
+		if !x {
+			return false
+		}
+	}
+	return true
+}
+
+//Verify checks that the cube can be solved by turning faces
+func (c *Cube) Verify() error {
+	if !c.piecesPresent() {
+		return ErrInvalidCube
+	}
+	twist := 0
+	for _, x := range c.CornerOrient {
+		twist += x
+	}
+	if twist%3 != 0 {
+		return ErrTwistedCorner
+	}
+	flip := 0
+	for _, x := range c.EdgeOrient {
+		flip += x
+	}
+	if flip%2 != 0 {
+		return ErrFlippedEdge
+	}
+	if Parity(c.CornerPerm[:]) != Parity(c.EdgePerm[:]) {
+		return ErrParity
+	}
+	return nil
+}
+
+//Parity returns 0 for an even permutation and 1 for an odd one
+func Parity(p []int) int {
+	s := 0
+	for i := len(p) - 1; i > 0; i-- {
+		for j := i - 1; j >= 0; j-- {
+			if p[j] > p[i] {
+				s++
+			}
+		}
+	}
+	return s % 2
+}
+
+//Twist is a number from 0 to 2186 for the orientation of the corners
+func (c *Cube) Twist() int {
+	r := 0
+	for i := URF; i < DRB; i++ {
+		r = 3*r + c.CornerOrient[i]
+	}
+	return r
+}
+
+func (c *Cube) SetTwist(twist int) {
+	parity := 0
+	for i := DRB - 1; i >= URF; i-- {
+		c.CornerOrient[i] = twist % 3
+		parity += c.CornerOrient[i]
+		twist /= 3
+	}
+	c.CornerOrient[DRB] = (3 - parity%3) % 3
+}
+
+//Flip is a number from 0 to 2047 for the orientation of the edges
+func (c *Cube) Flip() int {
+	r := 0
+	for i := UR; i < BR; i++ {
+		r = 2*r + c.EdgeOrient[i]
+	}
+	return r
+}
+
+func (c *Cube) SetFlip(flip int) {
+	parity := 0
+	for i := BR - 1; i >= UR; i-- {
+		c.EdgeOrient[i] = flip % 2
+		parity += c.EdgeOrient[i]
+		flip /= 2
+	}
+	c.EdgeOrient[BR] = (2 - parity%2) % 2
+}
+
+//PermIndex returns the lehmer code of the permutation p
+func PermIndex(p []int) int {
+	r := 0
+	for i := len(p) - 1; i > 0; i-- {
+		s := 0
+		for j := i - 1; j >= 0; j-- {
+			if p[j] > p[i] {
+				s++
+			}
+		}
+		r = (r + s) * i
+	}
+	return r
+}
+
+//SetPermIndex sets p to the permutation of base to base+len(p)-1 with lehmer code idx
+func SetPermIndex(p []int, idx, base int) {
+	n := len(p)
+	counts := make([]int, n)
+	for i := 1; i < n; i++ {
+		counts[i] = idx % (i + 1)
+		idx /= i + 1
+	}
+	used := make([]bool, n)
+	for i := n - 1; i >= 0; i-- {
+		k := counts[i]
+		for v := n - 1; v >= 0; v-- {
+			if used[v] {
+				continue
+			}
+			if k == 0 {
+				p[i] = v + base
+				used[v] = true
+				break
+			}
+			k--
+		}
+	}
+}
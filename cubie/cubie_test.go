@@ -0,0 +1,120 @@
+package cubie
+
+import (
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"testing"
+)
+
+var solvedCube = "000000000111111111222222222333333333444444444555555555"
+
+func TestFromBytecube(t *testing.T) {
+	data := []struct {
+		steps string
+		move  int
+	}{
+		{"U", 0},
+		{"U2", 1},
+		{"U'", 2},
+		{"R", 3},
+		{"F", 6},
+		{"D", 9},
+		{"L", 12},
+		{"B", 15},
+		{"B'", 17},
+	}
+	for _, x := range data {
+		c, _ := bytecube.NewCube(solvedCube)
+		r := rubikscuberunner.NewOfficialRunner(c)
+		r.Run(x.steps)
+		cc, err := FromBytecube(c)
+		if err != nil {
+			t.Error("Failed FromBytecube for ", x.steps, " got error: ", err)
+			continue
+		}
+		if *cc != *NewMoveCube(x.move) {
+			t.Error("Failed FromBytecube for ", x.steps, " got: ", *cc, " expected: ", *NewMoveCube(x.move))
+		}
+	}
+}
+
+func TestBytecube(t *testing.T) {
+	data := []string{
+		"",
+		"R",
+		"R U' B' L F R' U2 F2 L' D R U L'",
+		"D2 R' F2 U B2 L' D' F R2 U2 B L2 F' D R' U' L B2 F D'",
+	}
+	for _, x := range data {
+		c, _ := bytecube.NewCube("222222222333333333444444444555555555000000000111111111")
+		if x != "" {
+			rubikscuberunner.NewOfficialRunner(c).Run(x)
+		}
+		cc, err := FromBytecube(c)
+		if err != nil {
+			t.Error("Failed FromBytecube for ", x, " got error: ", err)
+			continue
+		}
+		if cc.Bytecube().String() != c.String() {
+			t.Error("Failed Bytecube for ", x, " got: ", cc.Bytecube().String(), " expected: ", c.String())
+		}
+	}
+}
+
+func TestMultiplyAndInverse(t *testing.T) {
+	c := NewCube()
+	for _, m := range []int{3, 0, 14, 7, 9, 16, 5} {
+		c.Move(m)
+	}
+	d := c.Inverse()
+	c.Multiply(d)
+	if !c.Solved() {
+		t.Error("Failed Inverse got: ", *c)
+	}
+	for m := 0; m < N_MOVE; m += 3 {
+		c := NewCube()
+		for i := 0; i < 4; i++ {
+			c.Move(m)
+		}
+		if !c.Solved() {
+			t.Error("Failed four turns of ", MoveName(m), " got: ", *c)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	data := []struct {
+		change func(c *Cube)
+		err    error
+	}{
+		{func(c *Cube) {}, nil},
+		{func(c *Cube) { c.CornerOrient[URF] = 1 }, ErrTwistedCorner},
+		{func(c *Cube) { c.EdgeOrient[UF] = 1 }, ErrFlippedEdge},
+		{func(c *Cube) { c.EdgePerm[UF], c.EdgePerm[UR] = c.EdgePerm[UR], c.EdgePerm[UF] }, ErrParity},
+		{func(c *Cube) { c.CornerPerm[URF] = UFL }, ErrInvalidCube},
+	}
+	for i, x := range data {
+		c := NewCube()
+		c.Move(4)
+		x.change(c)
+		if err := c.Verify(); err != x.err {
+			t.Error("Failed Verify ", i, " got: ", err, " expected: ", x.err)
+		}
+	}
+}
+
+func TestTwistAndFlip(t *testing.T) {
+	c := NewCube()
+	for i := 0; i < 2187; i++ {
+		c.SetTwist(i)
+		if c.Twist() != i {
+			t.Error("Failed Twist got: ", c.Twist(), " expected: ", i)
+		}
+	}
+	for i := 0; i < 2048; i++ {
+		c.SetFlip(i)
+		if c.Flip() != i {
+			t.Error("Failed Flip got: ", c.Flip(), " expected: ", i)
+		}
+	}
+}
@@ -1,8 +1,9 @@
 package depthfirst
 
 import (
-	"fmt"
+	"context"
 	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/solver"
 	"strconv"
 )
 
@@ -34,14 +35,32 @@ func NewFactory() *Factory {
 	return f
 }
 
+func init() {
+	solver.Register("depthfirst", func(ctx context.Context, c *bytecube.Cube, o solver.Options) (solver.Solver, error) {
+		s, err := NewSolver(c.String(), o.Target, NewFactory())
+		if err != nil {
+			return nil, err
+		}
+		return solver.Legacy(s), nil
+	})
+}
+
 type Solver struct {
 	funcs         []func(Cube, int) (bytecube.State, bool)
 	funcsLetter   []string
 	startingState string
 	factory       CubeFactory
+	stop          *solver.Stopper
+	observer      solver.Observer
 }
 
-func NewSolver(state string, factory CubeFactory) *Solver {
+//NewSolver returns a solver of the steps from state to target, or to the solved cube when target is "".
+//Once turned into official face turns by solver.Legacy they reach target exactly, see solver.Relative.
+func NewSolver(state, target string, factory CubeFactory) (*Solver, error) {
+	state, err := solver.RelativeState(state, target)
+	if err != nil {
+		return nil, err
+	}
 	s := new(Solver)
 	s.funcs = make([]func(Cube, int) (bytecube.State, bool), 9, 9)
 	s.funcsLetter = make([]string, 9, 9)
@@ -65,18 +84,34 @@ func NewSolver(state string, factory CubeFactory) *Solver {
 	}
 	s.startingState = state
 	s.factory = factory
-	return s
+	s.stop = solver.NewStopper(context.Background())
+	return s, nil
+}
+
+//SetObserver sets the observer that receives the progress of the solve, by default nothing is reported
+func (s *Solver) SetObserver(o solver.Observer) {
+	s.observer = o
 }
 
 func (s *Solver) Solve() string {
+	result, _ := s.SolveContext(context.Background())
+	return result
+}
+
+//SolveContext stops the search and returns a *solver.CancelError when ctx is canceled or its deadline passes
+func (s *Solver) SolveContext(ctx context.Context) (string, error) {
+	s.stop = solver.NewStopper(ctx)
 	c, _ := bytecube.NewCube(s.startingState)
 	state := c.State()
 	result, _ := s.SolveR(state, 0, 20, "")
-	return result
+	if err := s.stop.Err(); err != nil {
+		return "", err
+	}
+	return result, nil
 }
 
 func (s *Solver) SolveR(state bytecube.State, depth, maxDepth int, steps string) (string, int) {
-	if depth >= maxDepth {
+	if depth >= maxDepth || s.stop.Stopped() {
 		return "", -1
 	}
 	currentMaxDepth := maxDepth
@@ -87,8 +122,9 @@ func (s *Solver) SolveR(state bytecube.State, depth, maxDepth int, steps string)
 			cube := s.factory.New(state)
 			rState, rSolved := s.funcs[i](cube, j)
 			if rSolved {
-				fmt.Println("Found Solution, depth: ", depth+1)
-				return steps + s.funcsLetter[i] + strconv.Itoa(j), depth
+				solution := steps + s.funcsLetter[i] + strconv.Itoa(j)
+				s.observer.Notify(solver.Event{Kind: solver.SolutionFound, Depth: depth + 1, Solution: solution})
+				return solution, depth
 			}
 			rSteps, rDepth := s.SolveR(rState, depth+1, currentMaxDepth, steps+s.funcsLetter[i]+strconv.Itoa(j))
 			if rDepth != -1 && rDepth < currentMaxDepth {
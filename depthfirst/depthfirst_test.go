@@ -1,9 +1,13 @@
 package depthfirst
 
 import (
+	"context"
+	"errors"
 	"github.com/davidafox/rubikscubesolver/bytecube"
 	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"github.com/davidafox/rubikscubesolver/solver"
 	"testing"
+	"time"
 )
 
 func TestSolveRealCube(t *testing.T) {
@@ -17,7 +21,10 @@ func TestSolveRealCube(t *testing.T) {
 		c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
 		r := rubikscuberunner.NewRunner(c)
 		r.Run(x)
-		s := NewSolver(c.String(), NewFactory())
+		s, err := NewSolver(c.String(), "", NewFactory())
+		if err != nil {
+			t.Fatal(err)
+		}
 		result := s.Solve()
 		r.Run(result)
 		if !c.Solved() {
@@ -25,3 +32,19 @@ func TestSolveRealCube(t *testing.T) {
 		}
 	}
 }
+
+func TestSolveContext(t *testing.T) {
+	c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+	rubikscuberunner.NewRunner(c).Run("C1L0R1U0T1T0R1U0C1R0T0")
+	s, err := NewSolver(c.String(), "", NewFactory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	result, err := s.SolveContext(ctx)
+	var cancelErr *solver.CancelError
+	if !errors.As(err, &cancelErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("Failed SolveContext got: ", result, err, " expected: ", context.DeadlineExceeded)
+	}
+}
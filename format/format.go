@@ -0,0 +1,166 @@
+package format
+
+import (
+	"errors"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"strconv"
+	"strings"
+)
+
+//format reads and writes cube states in the layouts used by other tools.
+//Kociemba facelet strings list the U, R, F, D, L and B faces, each sticker being the letter of the face whose center
+//has its color. Color strings use the same layout with a letter for each color, such as W Y G B R O, and the center
+//of each face says which face a color belongs to. Every face is read in the same sticker order as bytecube,
+//so only the order of the faces changes.
+
+var ErrUnknownFormat = errors.New("The state is not digits, a Kociemba string or color letters")
+var ErrCenters = errors.New("The centers must be six different colors")
+var ErrUnknownColor = errors.New("A sticker's color is not the color of a center")
+
+//Format is a layout of a cube state
+type Format int
+
+const (
+	//Digits is the layout of bytecube.NewCube, the colors 0 to 5 with the faces in the order F L B R U D
+	Digits Format = iota
+	//Kociemba is the faces U R F D L B with each sticker the letter of a face
+	Kociemba
+	//Colors is the faces U R F D L B with a letter for each color
+	Colors
+)
+
+func (f Format) String() string {
+	switch f {
+	case Digits:
+		return "digits"
+	case Kociemba:
+		return "kociemba"
+	case Colors:
+		return "colors"
+	}
+	return "Format(" + strconv.Itoa(int(f)) + ")"
+}
+
+//faces holds the letters of the faces in the order of a Kociemba string
+const faces = "URFDLB"
+
+//sides holds the bytecube side of each face in the order of a Kociemba string
+var sides = [6]int{4, 3, 0, 5, 1, 2}
+
+//Scheme holds the color letter of each face in the order U R F D L B
+type Scheme [6]byte
+
+//WESTERN is the usual color scheme, white on top and green in front
+var WESTERN = Scheme{'W', 'R', 'G', 'Y', 'O', 'B'}
+
+//Detect returns the format of s. A string of 54 digits from 0 to 5 is Digits, 54 letters with the centers
+//U R F D L B in order is Kociemba and any other 54 letters are Colors.
+func Detect(s string) (Format, error) {
+	if len(s) != 54 {
+		return Digits, bytecube.ErrIncorrectNumber
+	}
+	if strings.Trim(s, "012345") == "" {
+		return Digits, nil
+	}
+	for i := 0; i < len(s); i++ {
+		if !isLetter(s[i]) {
+			return Digits, ErrUnknownFormat
+		}
+	}
+	for i := 0; i < 6; i++ {
+		if s[9*i+4] != faces[i] {
+			return Colors, nil
+		}
+	}
+	return Kociemba, nil
+}
+
+func isLetter(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+//ToDigits returns s converted from the format it is in to the format of bytecube.NewCube
+func ToDigits(s string) (string, Format, error) {
+	f, err := Detect(s)
+	if err != nil || f == Digits {
+		return s, f, err
+	}
+	digits, err := lettersToDigits(s)
+	return digits, f, err
+}
+
+//Parse returns the cube given by s in any of the formats, it is not validated
+func Parse(s string) (*bytecube.Cube, Format, error) {
+	digits, f, err := ToDigits(s)
+	if err != nil {
+		return nil, f, err
+	}
+	c, err := bytecube.NewCube(digits)
+	return c, f, err
+}
+
+//NewValidCube is bytecube.NewValidCube for a state in any of the formats
+func NewValidCube(s string) (*bytecube.Cube, error) {
+	digits, _, err := ToDigits(s)
+	if err != nil {
+		return nil, err
+	}
+	return bytecube.NewValidCube(digits)
+}
+
+//lettersToDigits converts a Kociemba or color string, a letter's color is the bytecube side whose center has it
+func lettersToDigits(s string) (string, error) {
+	color := make(map[byte]int)
+	for i := 0; i < 6; i++ {
+		color[s[9*i+4]] = sides[i]
+	}
+	if len(color) != 6 {
+		return "", ErrCenters
+	}
+	var result [54]byte
+	for i := 0; i < 6; i++ {
+		for spot := 0; spot < 9; spot++ {
+			c, ok := color[s[9*i+spot]]
+			if !ok {
+				return "", ErrUnknownColor
+			}
+			result[9*sides[i]+spot] = byte('0' + c)
+		}
+	}
+	return string(result[:]), nil
+}
+
+//letters returns the stickers of c in the order of a Kociemba string with the letter of each center's face
+func letters(c *bytecube.Cube, scheme Scheme) (string, error) {
+	s := c.String()
+	letter := make(map[byte]byte)
+	for i := 0; i < 6; i++ {
+		letter[s[9*sides[i]+4]] = scheme[i]
+	}
+	if len(letter) != 6 {
+		return "", ErrCenters
+	}
+	var result [54]byte
+	for i := 0; i < 6; i++ {
+		for spot := 0; spot < 9; spot++ {
+			x, ok := letter[s[9*sides[i]+spot]]
+			if !ok {
+				return "", ErrUnknownColor
+			}
+			result[9*i+spot] = x
+		}
+	}
+	return string(result[:]), nil
+}
+
+//ToKociemba returns c as a Kociemba facelet string
+func ToKociemba(c *bytecube.Cube) (string, error) {
+	var scheme Scheme
+	copy(scheme[:], faces)
+	return letters(c, scheme)
+}
+
+//ToColors returns c as color letters using scheme for the color of each face's center
+func ToColors(c *bytecube.Cube, scheme Scheme) (string, error) {
+	return letters(c, scheme)
+}
@@ -0,0 +1,86 @@
+package format
+
+import (
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"strings"
+	"testing"
+)
+
+const solved = "000000000111111111222222222333333333444444444555555555"
+
+func TestToKociemba(t *testing.T) {
+	data := []struct {
+		moves    string
+		expected string
+	}{
+		{"", "UUUUUUUUURRRRRRRRRFFFFFFFFFDDDDDDDDDLLLLLLLLLBBBBBBBBB"},
+		{"R", "UUFUUFUUFRRRRRRRRRFFDFFDFFDDDBDDBDDBLLLLLLLLLUBBUBBUBB"},
+		{"U", "UUUUUUUUUBBBRRRRRRRRRFFFFFFDDDDDDDDDFFFLLLLLLLLLBBBBBB"},
+		{"F", "UUUUUULLLURRURRURRFFFFFFFFFRRRDDDDDDLLDLLDLLDBBBBBBBBB"},
+	}
+	for _, x := range data {
+		c, _ := bytecube.NewCube(solved)
+		rubikscuberunner.NewOfficialRunner(c).Run(x.moves)
+		got, err := ToKociemba(c)
+		if err != nil || got != x.expected {
+			t.Error("Failed ToKociemba after ", x.moves, " got: ", got, err, " expected: ", x.expected)
+		}
+		back, f, err := Parse(got)
+		if err != nil || f != Kociemba || back.String() != c.String() {
+			t.Error("Failed Parse of ", got, " got: ", back, f, err)
+		}
+	}
+}
+
+func TestToColors(t *testing.T) {
+	c, _ := bytecube.NewCube(solved)
+	rubikscuberunner.NewOfficialRunner(c).Run("R U R' U' F2 D L' B")
+	colors, err := ToColors(c, WESTERN)
+	if err != nil {
+		t.Fatal("Failed ToColors got error: ", err)
+	}
+	if strings.Trim(colors, "WYGBRO") != "" || colors[4] != 'W' || colors[22] != 'G' {
+		t.Error("Failed ToColors got: ", colors)
+	}
+	back, f, err := Parse(colors)
+	if err != nil || f != Colors || back.String() != c.String() {
+		t.Error("Failed Parse of ", colors, " got: ", back, f, err)
+	}
+	//lower case letters and any scheme work as long as the centers differ
+	colors, _ = ToColors(c, Scheme{'y', 'b', 'r', 'w', 'g', 'o'})
+	back, _, err = Parse(colors)
+	if err != nil || back.String() != c.String() {
+		t.Error("Failed Parse of ", colors, " got: ", back, err)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	data := []struct {
+		s      string
+		format Format
+		err    error
+	}{
+		{solved, Digits, nil},
+		{"UUUUUUUUURRRRRRRRRFFFFFFFFFDDDDDDDDDLLLLLLLLLBBBBBBBBB", Kociemba, nil},
+		{"WWWWWWWWWRRRRRRRRRGGGGGGGGGYYYYYYYYYOOOOOOOOOBBBBBBBBB", Colors, nil},
+		{"123", Digits, bytecube.ErrIncorrectNumber},
+		{strings.Repeat("7", 54), Digits, ErrUnknownFormat},
+		{strings.Repeat("W", 53) + "!", Digits, ErrUnknownFormat},
+	}
+	for _, x := range data {
+		f, err := Detect(x.s)
+		if f != x.format || err != x.err {
+			t.Error("Failed Detect for ", x.s, " got: ", f, err, " expected: ", x.format, x.err)
+		}
+	}
+	if _, _, err := ToDigits(strings.Repeat("W", 54)); err != ErrCenters {
+		t.Error("Failed ToDigits got: ", err, " expected: ", ErrCenters)
+	}
+	if _, _, err := ToDigits("WWWWWWWWXRRRRRRRRRGGGGGGGGGYYYYYYYYYOOOOOOOOOBBBBBBBBB"); err != ErrUnknownColor {
+		t.Error("Failed ToDigits got: ", err, " expected: ", ErrUnknownColor)
+	}
+	if digits, f, err := ToDigits(solved); digits != solved || f != Digits || err != nil {
+		t.Error("Failed ToDigits of digits got: ", digits, f, err)
+	}
+}
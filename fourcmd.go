@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/reduction"
+	"github.com/davidafox/rubikscubesolver/rubikscube"
+	"github.com/davidafox/rubikscubesolver/solver"
+	"os"
+	"strings"
+)
+
+//runFour solves a 4x4 cube, it is run with: rubikscubesolver 4x4 [-state state | -scramble moves] [-solver name]
+func runFour(args []string) int {
+	flags := flag.NewFlagSet("4x4", flag.ExitOnError)
+	state := flags.String("state", "", "the 96 digit state to solve")
+	scrambleMoves := flags.String("scramble", "", "moves in SiGN notation applied to a solved cube to give the state to solve instead of -state")
+	name := flags.String("solver", "twophase", "the solver used once the cube is reduced to a 3x3: "+strings.Join(solver.Names(), ", "))
+	maxLength := flags.Int("maxlength", 0, "the longest solution the twophase solver looks for, 0 for its default")
+	flags.Parse(args)
+	if !solver.Registered(*name) {
+		fmt.Fprintln(os.Stderr, solver.ErrUnknownSolver, *name)
+		return EXIT_INVALID_INPUT
+	}
+	var c *rubikscube.Cube
+	switch {
+	case *state != "" && *scrambleMoves != "":
+		fmt.Fprintln(os.Stderr, "Give -state or -scramble, not both")
+		return EXIT_INVALID_INPUT
+	case *scrambleMoves != "":
+		seq, err := reduction.Parse(*scrambleMoves)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return EXIT_INVALID_INPUT
+		}
+		c = rubikscube.NewCube(reduction.SOLVED, reduction.SIZE)
+		reduction.Apply(c, seq)
+	default:
+		c = rubikscube.NewCube(strings.TrimSpace(*state), reduction.SIZE)
+	}
+	s, err := reduction.NewSolver(c, *name, solver.Options{MaxLength: *maxLength})
+	switch err {
+	case nil:
+	case reduction.ErrIncorrectNumber, bytecube.ErrInvalidColor:
+		fmt.Fprintln(os.Stderr, err)
+		return EXIT_INVALID_INPUT
+	default:
+		fmt.Fprintln(os.Stderr, err)
+		return EXIT_UNSOLVABLE
+	}
+	solution, err := s.Solve()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return EXIT_ERROR
+	}
+	fmt.Println(solution)
+	return EXIT_OK
+}
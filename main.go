@@ -2,21 +2,76 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	_ "github.com/davidafox/rubikscubesolver/basic"
+	_ "github.com/davidafox/rubikscubesolver/breadthfirst"
 	"github.com/davidafox/rubikscubesolver/bytecube"
-	"github.com/davidafox/rubikscubesolver/combined"
+	_ "github.com/davidafox/rubikscubesolver/combined"
+	_ "github.com/davidafox/rubikscubesolver/depthfirst"
+	"github.com/davidafox/rubikscubesolver/format"
+	"github.com/davidafox/rubikscubesolver/notation"
+	_ "github.com/davidafox/rubikscubesolver/optimal"
+	"github.com/davidafox/rubikscubesolver/render"
+	"github.com/davidafox/rubikscubesolver/repl"
 	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"github.com/davidafox/rubikscubesolver/server"
+	"github.com/davidafox/rubikscubesolver/solver"
+	_ "github.com/davidafox/rubikscubesolver/twophase"
 	"log"
 	"os"
+	"path/filepath"
 	"runtime/pprof"
+	"strings"
 	"time"
 )
 
+var solverFlags = newSolverFlags(flag.CommandLine)
 var cpuprofile = flag.String("cpuprofile", "", "write cpu profile to file")
-var depth = flag.Int("depth", 6, "specify the depth to use breadth-first seach")
+var progress = flag.Bool("progress", true, "print the progress of the solve to stderr")
+var stateFlag = flag.String("state", "", "the state to solve instead of reading it from stdin")
+var scrambleFlag = flag.String("scramble", "", "moves in official notation applied to a solved cube to give the state to solve")
+var output = flag.String("output", "text", "the output format: text or json")
+var timeout = flag.Duration("timeout", 0, "the longest the solve may take, 0 for no limit")
+var net = flag.String("net", "none", "print the cube before and after solving as a net: none, ascii or ansi")
+var replFlag = flag.Bool("repl", false, "turn the cube a line of moves at a time with undo, redo and solve instead of solving it once")
+var targetFlag = flag.String("target", "", "the state to solve to instead of the solved cube, in any of the state formats")
+var colors = flag.String("colors", "", "the ANSI 256 color codes of the colors 0 to 5 for -net ansi, such as 28,208,21,196,15,226")
+
+//exit codes
+const (
+	EXIT_OK            = 0
+	EXIT_ERROR         = 1
+	EXIT_INVALID_INPUT = 2
+	EXIT_UNSOLVABLE    = 3
+	EXIT_TIMEOUT       = 4
+)
 
 func main() {
+	os.Exit(run())
+}
+
+//run is main returning the exit code so deferred calls run before exiting
+func run() int {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "scramble":
+			return runScramble(os.Args[2:])
+		case "serve":
+			return runServe(os.Args[2:])
+		case "batch":
+			return runBatch(os.Args[2:])
+		case "svg":
+			return runSVG(os.Args[2:])
+		case "pocket":
+			return runPocket(os.Args[2:])
+		case "4x4":
+			return runFour(os.Args[2:])
+		}
+	}
 	flag.Parse()
 	if *cpuprofile != "" {
 		f, err := os.Create(*cpuprofile)
@@ -26,42 +81,293 @@ func main() {
 		pprof.StartCPUProfile(f)
 		defer pprof.StopCPUProfile()
 	}
-	fmt.Println("Enter cube state with numbers 0-5 representing the colors starting with the Side facing you and going clockwise around the cube followed by the top and then the bottom. Type quit to quit.")
-	fmt.Println("Example: 000000000111111111222222222333333333444444444555555555")
-	scanner := bufio.NewScanner(os.Stdin)
-	valid := false
-	var err error
-	var c *bytecube.Cube
-	for !valid {
-		scanner.Scan()
-		state := scanner.Text()
-		if state == "quit" {
-			return
+	if *output != "text" && *output != "json" {
+		fmt.Fprintln(os.Stderr, "Unknown output format:", *output)
+		return EXIT_INVALID_INPUT
+	}
+	if *net != "none" && *net != "ascii" && *net != "ansi" {
+		fmt.Fprintln(os.Stderr, "Unknown net format:", *net)
+		return EXIT_INVALID_INPUT
+	}
+	scheme := render.DEFAULT_SCHEME
+	if *colors != "" {
+		var err error
+		if scheme, err = render.ParseScheme(*colors); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return EXIT_INVALID_INPUT
 		}
-		c, err = bytecube.NewCube(state)
+	}
+	resp := &server.Response{Solver: *solverFlags.name}
+	if !solver.Registered(*solverFlags.name) {
+		return fail(resp, &server.Error{Code: server.CodeUnknownSolver, Message: solver.ErrUnknownSolver.Error() + " " + *solverFlags.name}, EXIT_INVALID_INPUT)
+	}
+	if *replFlag {
+		return runREPL(scheme)
+	}
+	c, e, code := readCube()
+	if e != nil {
+		return fail(resp, e, code)
+	}
+	if c == nil {
+		return EXIT_OK
+	}
+	resp.State = c.String()
+	printNet(c, scheme)
+	options := solverFlags.options()
+	//reached is true when the cube is solved or in the target state
+	reached := c.Solved
+	if *targetFlag != "" {
+		target, _, err := format.ToDigits(strings.TrimSpace(*targetFlag))
 		if err != nil {
-			fmt.Println(err)
+			err = &solver.TargetError{Err: err}
 		} else {
-			valid, err = c.Validate()
-			if err != nil {
-				fmt.Println(err)
-			}
+			_, err = solver.Relative(c, target)
+		}
+		if err != nil {
+			return fail(resp, server.TargetError(err), targetExitCode(err))
 		}
+		options.Target = target
+		reached = func() bool { return c.String() == target }
 	}
-	if c.Solved() {
-		fmt.Println("The cube is already solved.")
-		return
+	if reached() {
+		if *output == "json" {
+			writeJSON(resp)
+		} else if *targetFlag != "" {
+			fmt.Println("The cube is already in the target state.")
+		} else {
+			fmt.Println("The cube is already solved.")
+		}
+		return EXIT_OK
+	}
+	//the timeout covers creating the solver, which can generate tables, as well as the solve
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	var cancelErr *solver.CancelError
+	s, err := solver.NewContext(ctx, *solverFlags.name, c, options)
+	if errors.As(err, &cancelErr) {
+		return fail(resp, &server.Error{Code: server.CodeTimeout, Message: err.Error()}, EXIT_TIMEOUT)
+	}
+	if err != nil {
+		return fail(resp, &server.Error{Code: server.CodeNoSolution, Message: err.Error()}, EXIT_ERROR)
+	}
+	if *progress {
+		s.SetObserver(printProgress)
 	}
-	cf := combined.NewFactory()
-	r := rubikscuberunner.NewOfficialRunner(c)
-	//	r.Run("R U' B' L F R' U2 F2 L' D R U L'")
-	s := combined.NewSolver(c.String(), cf, *depth)
 	startTime := time.Now()
-	solution := s.Solve()
+	solution, err := s.SolveContext(ctx)
 	runtime := time.Since(startTime)
-	r.Run(solution)
+	resp.ElapsedMS = float64(runtime) / float64(time.Millisecond)
+	if errors.As(err, &cancelErr) {
+		return fail(resp, &server.Error{Code: server.CodeTimeout, Message: err.Error()}, EXIT_TIMEOUT)
+	}
+	if err != nil {
+		return fail(resp, &server.Error{Code: server.CodeNoSolution, Message: err.Error()}, EXIT_ERROR)
+	}
+	if err := rubikscuberunner.NewOfficialRunner(c).Run(solution); err != nil {
+		return fail(resp, &server.Error{Code: server.CodeNoSolution, Message: err.Error()}, EXIT_ERROR)
+	}
+	resp.Solution = solution
+	resp.Moves = len(strings.Fields(solution))
+	printNet(c, scheme)
+	if *output == "json" {
+		if !reached() {
+			return fail(resp, &server.Error{Code: server.CodeNoSolution, Message: "The solution does not solve the cube"}, EXIT_ERROR)
+		}
+		writeJSON(resp)
+		return EXIT_OK
+	}
 	fmt.Println(solution)
 	fmt.Println("Time: ", runtime)
-	fmt.Println("Solved: ", c.Solved())
+	fmt.Println("Solved: ", reached())
+	if !reached() {
+		return EXIT_ERROR
+	}
+	return EXIT_OK
+}
+
+//readCube returns the cube given by -state or -scramble, or read from stdin.
+//The cube is nil without an error when quit is typed at the prompt.
+func readCube() (*bytecube.Cube, *server.Error, int) {
+	switch {
+	case *stateFlag != "" && *scrambleFlag != "":
+		return nil, &server.Error{Code: server.CodeBadRequest, Message: "Give -state or -scramble, not both"}, EXIT_INVALID_INPUT
+	case *scrambleFlag != "":
+		seq, err := notation.Parse(*scrambleFlag)
+		if err != nil {
+			e := &server.Error{Code: server.CodeBadScramble, Message: err.Error()}
+			var pe *notation.ParseError
+			if errors.As(err, &pe) {
+				e.Offset = &pe.Offset
+			}
+			return nil, e, EXIT_INVALID_INPUT
+		}
+		c, _ := bytecube.NewCube(bytecube.SOLVED)
+		rubikscuberunner.NewOfficialRunner(c).RunSequence(seq)
+		return c, nil, EXIT_OK
+	case *stateFlag != "":
+		c, err := newCube(strings.TrimSpace(*stateFlag))
+		if err != nil {
+			return nil, server.StateError(err), stateExitCode(err)
+		}
+		return c, nil, EXIT_OK
+	}
+	//the prompt goes to stderr when stdout is for JSON
+	prompt := os.Stdout
+	if *output == "json" {
+		prompt = os.Stderr
+	}
+	fmt.Fprintln(prompt, "Enter cube state with numbers 0-5 representing the colors starting with the Side facing you and going clockwise around the cube followed by the top and then the bottom. Type quit to quit.")
+	fmt.Fprintln(prompt, "Example: 000000000111111111222222222333333333444444444555555555")
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		state := scanner.Text()
+		if state == "quit" {
+			return nil, nil, EXIT_OK
+		}
+		c, err := newCube(strings.TrimSpace(state))
+		if err == nil {
+			return c, nil, EXIT_OK
+		}
+		fmt.Fprintln(prompt, err)
+	}
+	return nil, &server.Error{Code: server.CodeBadRequest, Message: "No state was entered"}, EXIT_INVALID_INPUT
+}
 
+//newCube returns the cube given by a state in any of the formats. With -target it is not checked to be a cube that can be
+//solved, since solver.Relative checks the cube and the target together and a cube that can't be solved reaches a target
+//that can't either. The REPL ignores -target.
+func newCube(state string) (*bytecube.Cube, error) {
+	if *targetFlag == "" || *replFlag {
+		return format.NewValidCube(state)
+	}
+	c, _, err := format.Parse(state)
+	return c, err
+}
+
+//stateExitCode returns the exit code for an error from format.NewValidCube,
+//a state that isn't 54 stickers in one of the formats is invalid input and anything else is a cube that can't be solved
+func stateExitCode(err error) int {
+	switch err {
+	case bytecube.ErrIncorrectNumber, bytecube.ErrInvalidColor, format.ErrUnknownFormat, format.ErrUnknownColor:
+		return EXIT_INVALID_INPUT
+	}
+	return EXIT_UNSOLVABLE
+}
+
+//targetExitCode returns the exit code for an error from solver.Relative, a target that isn't a cube is invalid input
+//and one that can't be reached can't be solved
+func targetExitCode(err error) int {
+	var targetErr *solver.TargetError
+	switch {
+	case err == solver.ErrDifferentOrbit:
+		return EXIT_UNSOLVABLE
+	case errors.As(err, &targetErr):
+		return EXIT_INVALID_INPUT
+	}
+	return stateExitCode(err)
+}
+
+//runREPL starts an interactive session with the cube given by -state or -scramble, or a solved cube
+func runREPL(scheme render.Scheme) int {
+	c, _ := bytecube.NewCube(bytecube.SOLVED)
+	if *stateFlag != "" || *scrambleFlag != "" {
+		var e *server.Error
+		var code int
+		if c, e, code = readCube(); e != nil {
+			fmt.Fprintln(os.Stderr, e.Message)
+			return code
+		}
+	}
+	config := repl.Config{Solver: *solverFlags.name, Options: solverFlags.options(), Timeout: *timeout}
+	if *net == "ansi" {
+		config.Net = func(c *bytecube.Cube) string {
+			return render.ANSI(c, scheme)
+		}
+	}
+	fmt.Println("Enter moves such as R U R' U' or a command, help lists the commands.")
+	if err := repl.Run(context.Background(), repl.New(c, config), os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return EXIT_ERROR
+	}
+	return EXIT_OK
+}
+
+//printNet prints c as the net chosen by -net, to stderr when stdout holds the JSON output
+func printNet(c *bytecube.Cube, scheme render.Scheme) {
+	w := os.Stdout
+	if *output == "json" {
+		w = os.Stderr
+	}
+	switch *net {
+	case "ascii":
+		fmt.Fprintln(w, render.ASCII(c))
+	case "ansi":
+		fmt.Fprintln(w, render.ANSI(c, scheme))
+	}
+}
+
+//fail reports e in the output format and returns code
+func fail(resp *server.Response, e *server.Error, code int) int {
+	if *output == "json" {
+		resp.Error = e
+		writeJSON(resp)
+	} else {
+		fmt.Fprintln(os.Stderr, e.Message)
+	}
+	return code
+}
+
+func writeJSON(v interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+//solverOptions holds the flags that choose a solver and its options, the main command and subcommands share them
+type solverOptions struct {
+	name      *string
+	depth     *int
+	memory    *int
+	maxLength *int
+	tables    *string
+}
+
+func newSolverFlags(fs *flag.FlagSet) *solverOptions {
+	f := new(solverOptions)
+	f.name = fs.String("solver", "combined", "the solver to use: "+strings.Join(solver.Names(), ", "))
+	f.depth = fs.Int("depth", 6, "specify the depth to use breadth-first seach")
+	f.memory = fs.Int("memory", 0, "memory budget in MB for the breadth-first states, 0 for no limit")
+	f.maxLength = fs.Int("maxlength", 0, "the longest solution the twophase solver looks for, 0 for its default")
+	f.tables = fs.String("tables", defaultTableDir(), "directory the optimal solver loads its pattern databases from and saves them to, empty to generate them on every run")
+	return f
+}
+
+//defaultTableDir is the user's cache directory so the pattern databases are only generated on the first run
+func defaultTableDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "rubikscubesolver")
+}
+
+func (f *solverOptions) options() solver.Options {
+	return solver.Options{Depth: *f.depth, MemoryBudget: *f.memory << 20, MaxLength: *f.maxLength, TableDir: *f.tables}
+}
+
+func printProgress(e solver.Event) {
+	switch e.Kind {
+	case solver.LayerDone:
+		fmt.Fprintln(os.Stderr, "On step ", e.Depth, "(", e.Side, ")", "states: ", e.Frontier[e.Side])
+	case solver.DepthStarted:
+		fmt.Fprintln(os.Stderr, "Depth: ", e.Depth)
+	case solver.SolutionFound:
+		fmt.Fprintln(os.Stderr, "Found Solution, depth: ", e.Depth)
+	case solver.ExpansionStopped:
+		fmt.Fprintln(os.Stderr, "Memory budget reached, side ", e.Side, " stopped at depth ", e.Depth)
+	}
 }
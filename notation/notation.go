@@ -0,0 +1,436 @@
+package notation
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+//notation parses move sequences written in WCA notation.
+//U D R L F B turn a face, Uw or u turn the face and the middle layer behind it,
+//M E S turn a middle layer and x y z rotate the whole cube. For bigger cubes 2R turns only the slice next to R, as
+//in SiGN notation, which on a 3x3 is a middle layer.
+//A move may be followed by a number of turns and a ' for counterclockwise, so R2' is allowed.
+//Moves can be grouped and repeated with (R U R' U')3 or inverted with (R U)', [A: B] is the conjugate A B A'
+//and [A, B] the commutator A B A' B'. Spaces between moves are optional and // starts a comment that runs
+//to the end of the line.
+
+var ErrUnknownMove = errors.New("Unknown move")
+var ErrUnclosedGroup = errors.New("Group is not closed")
+var ErrUnexpectedClose = errors.New("Group closed without being opened")
+var ErrBadCount = errors.New("Invalid number of turns")
+var ErrBadBracket = errors.New("Bracket is not [A: B] or [A, B]")
+var ErrTooLong = errors.New("Sequence is too long")
+
+//MAX_REPEAT is the most times a group may be repeated
+const MAX_REPEAT = 1000
+
+//MAX_MOVES is the most moves a group may expand to
+const MAX_MOVES = 100000
+
+//ParseError gives the byte offset in the parsed string where parsing failed
+type ParseError struct {
+	Offset int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return e.Err.Error() + " at position " + strconv.Itoa(e.Offset)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+//Move is a single turn.
+//Face is one of UDRLFB, MES or xyz. Turns is the number of clockwise quarter turns, 1 to 3.
+//Inner is the slice next to a face without the face, it is never Wide.
+type Move struct {
+	Face  byte
+	Wide  bool
+	Turns int
+	Inner bool
+}
+
+//Sequence is a list of moves with groups and repetitions expanded
+type Sequence []Move
+
+const faces = "UDRLFB"
+const slices = "MES"
+const rotations = "xyz"
+
+func (m Move) String() string {
+	s := string(m.Face)
+	if m.Inner {
+		s = "2" + s
+	}
+	if m.Wide {
+		s += "w"
+	}
+	switch m.Turns {
+	case 2:
+		s += "2"
+	case 3:
+		s += "'"
+	}
+	return s
+}
+
+//String returns the moves in official notation separated by spaces
+func (s Sequence) String() string {
+	moves := make([]string, len(s))
+	for i, m := range s {
+		moves[i] = m.String()
+	}
+	return strings.Join(moves, " ")
+}
+
+//Inverse returns the move that undoes m
+func (m Move) Inverse() Move {
+	m.Turns = 4 - m.Turns
+	return m
+}
+
+//Inverse returns the moves that undo s
+func (s Sequence) Inverse() Sequence {
+	result := make(Sequence, len(s))
+	for i, m := range s {
+		result[len(s)-1-i] = m.Inverse()
+	}
+	return result
+}
+
+//Parse reads a move sequence, a *ParseError is returned for anything that isn't a move
+func Parse(s string) (Sequence, error) {
+	p := &parser{s: s}
+	seq, err := p.sequence(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.s) {
+		return nil, &ParseError{p.pos, ErrUnexpectedClose}
+	}
+	return seq, nil
+}
+
+//MustParse is Parse for sequences known to be valid, it panics on an error
+func MustParse(s string) Sequence {
+	seq, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return seq
+}
+
+type parser struct {
+	s   string
+	pos int
+}
+
+//sequence reads moves until the end of the string or a character that ends a group at depth > 0
+func (p *parser) sequence(depth int) (Sequence, error) {
+	seq := Sequence{}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.s) {
+			return seq, nil
+		}
+		c := p.s[p.pos]
+		switch {
+		case strings.IndexByte(")]:,", c) >= 0:
+			if depth == 0 {
+				return nil, &ParseError{p.pos, ErrUnexpectedClose}
+			}
+			return seq, nil
+		case c == '(':
+			group, err := p.group(depth)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, group...)
+		case c == '[':
+			group, err := p.bracket(depth)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, group...)
+		default:
+			m, err := p.move()
+			if err != nil {
+				return nil, err
+			}
+			if m.Turns != 0 {
+				seq = append(seq, m)
+			}
+		}
+	}
+}
+
+//group reads a sequence in round brackets and its repetitions
+func (p *parser) group(depth int) (Sequence, error) {
+	start := p.pos
+	p.pos++
+	group, err := p.sequence(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos >= len(p.s) {
+		return nil, &ParseError{start, ErrUnclosedGroup}
+	}
+	if p.s[p.pos] != ')' {
+		return nil, &ParseError{p.pos, ErrUnexpectedClose}
+	}
+	p.pos++
+	return p.repeat(group)
+}
+
+//bracket reads a conjugate or commutator and its repetitions
+func (p *parser) bracket(depth int) (Sequence, error) {
+	start := p.pos
+	p.pos++
+	a, err := p.sequence(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos >= len(p.s) {
+		return nil, &ParseError{start, ErrUnclosedGroup}
+	}
+	separator := p.s[p.pos]
+	if separator != ':' && separator != ',' {
+		return nil, &ParseError{p.pos, ErrBadBracket}
+	}
+	p.pos++
+	b, err := p.sequence(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos >= len(p.s) {
+		return nil, &ParseError{start, ErrUnclosedGroup}
+	}
+	if p.s[p.pos] != ']' {
+		return nil, &ParseError{p.pos, ErrBadBracket}
+	}
+	p.pos++
+	group := append(append(Sequence{}, a...), b...)
+	group = append(group, a.Inverse()...)
+	if separator == ',' {
+		group = append(group, b.Inverse()...)
+	}
+	return p.repeat(group)
+}
+
+//repeat reads the count and ' after a group and returns the group repeated
+func (p *parser) repeat(group Sequence) (Sequence, error) {
+	countStart := p.pos
+	times, inverse, err := p.suffix()
+	if err != nil {
+		return nil, err
+	}
+	if times > MAX_REPEAT {
+		return nil, &ParseError{countStart, ErrBadCount}
+	}
+	if len(group)*times > MAX_MOVES {
+		return nil, &ParseError{countStart, ErrTooLong}
+	}
+	if inverse {
+		group = group.Inverse()
+	}
+	seq := make(Sequence, 0, len(group)*times)
+	for i := 0; i < times; i++ {
+		seq = append(seq, group...)
+	}
+	return seq, nil
+}
+
+//skipSpace skips whitespace and comments
+func (p *parser) skipSpace() {
+	for p.pos < len(p.s) {
+		switch {
+		case strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0:
+			p.pos++
+		case strings.HasPrefix(p.s[p.pos:], "//"):
+			end := strings.IndexByte(p.s[p.pos:], '\n')
+			if end < 0 {
+				p.pos = len(p.s)
+			} else {
+				p.pos += end + 1
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) move() (Move, error) {
+	start := p.pos
+	c := p.s[p.pos]
+	m := Move{Face: c}
+	switch {
+	case strings.IndexByte(faces, c) >= 0:
+		p.pos++
+		if p.pos < len(p.s) && p.s[p.pos] == 'w' {
+			m.Wide = true
+			p.pos++
+		}
+	case strings.IndexByte(strings.ToLower(faces), c) >= 0:
+		m.Face = strings.ToUpper(string(c))[0]
+		m.Wide = true
+		p.pos++
+	case strings.IndexByte(slices, c) >= 0 || strings.IndexByte(rotations, c) >= 0:
+		p.pos++
+	case c == '2' && p.pos+1 < len(p.s) && strings.IndexByte(faces, p.s[p.pos+1]) >= 0:
+		m.Face = p.s[p.pos+1]
+		m.Inner = true
+		p.pos += 2
+		//an inner slice can't also be wide
+		if p.pos < len(p.s) && p.s[p.pos] == 'w' {
+			return m, &ParseError{start, ErrUnknownMove}
+		}
+	default:
+		return m, &ParseError{start, ErrUnknownMove}
+	}
+	times, inverse, err := p.suffix()
+	if err != nil {
+		return m, err
+	}
+	if inverse {
+		times = -times
+	}
+	m.Turns = (times%4 + 4) % 4
+	return m, nil
+}
+
+//suffix reads an optional number of turns followed by an optional '
+func (p *parser) suffix() (int, bool, error) {
+	start := p.pos
+	for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+		p.pos++
+	}
+	times := 1
+	if p.pos > start {
+		n, err := strconv.Atoi(p.s[start:p.pos])
+		if err != nil || n == 0 {
+			return 0, false, &ParseError{start, ErrBadCount}
+		}
+		times = n
+	}
+	switch {
+	case strings.HasPrefix(p.s[p.pos:], "'"):
+		p.pos++
+		return times, true, nil
+	case strings.HasPrefix(p.s[p.pos:], "’"):
+		p.pos += len("’")
+		return times, true, nil
+	}
+	return times, false, nil
+}
+
+//replacement is a move that isn't a face turn written as face turns followed by a whole cube rotation
+type replacement struct {
+	turns    Sequence
+	rotation Move
+}
+
+//replacements holds the replacement of a single clockwise turn of each move that isn't a face turn.
+//Turning a face with the middle layer is the same as turning the opposite face and rotating the cube.
+var replacements = map[Move]replacement{
+	{'R', true, 1, false}:  {Sequence{{'L', false, 1, false}}, Move{'x', false, 1, false}},
+	{'L', true, 1, false}:  {Sequence{{'R', false, 1, false}}, Move{'x', false, 3, false}},
+	{'U', true, 1, false}:  {Sequence{{'D', false, 1, false}}, Move{'y', false, 1, false}},
+	{'D', true, 1, false}:  {Sequence{{'U', false, 1, false}}, Move{'y', false, 3, false}},
+	{'F', true, 1, false}:  {Sequence{{'B', false, 1, false}}, Move{'z', false, 1, false}},
+	{'B', true, 1, false}:  {Sequence{{'F', false, 1, false}}, Move{'z', false, 3, false}},
+	{'M', false, 1, false}: {Sequence{{'R', false, 1, false}, {'L', false, 3, false}}, Move{'x', false, 3, false}},
+	{'E', false, 1, false}: {Sequence{{'U', false, 1, false}, {'D', false, 3, false}}, Move{'y', false, 3, false}},
+	{'S', false, 1, false}: {Sequence{{'F', false, 3, false}, {'B', false, 1, false}}, Move{'z', false, 1, false}},
+	{'x', false, 1, false}: {Sequence{}, Move{'x', false, 1, false}},
+	{'y', false, 1, false}: {Sequence{}, Move{'y', false, 1, false}},
+	{'z', false, 1, false}: {Sequence{}, Move{'z', false, 1, false}},
+}
+
+//innerSlices holds the middle layer a single clockwise turn of the slice next to each face is on a 3x3
+var innerSlices = map[byte]Move{
+	'R': {'M', false, 3, false},
+	'L': {'M', false, 1, false},
+	'U': {'E', false, 3, false},
+	'D': {'E', false, 1, false},
+	'F': {'S', false, 1, false},
+	'B': {'S', false, 3, false},
+}
+
+//threeByThree returns the move that turns the same layers of a 3x3 as m, an inner slice is a middle layer
+func (m Move) threeByThree() Move {
+	if !m.Inner {
+		return m
+	}
+	slice := innerSlices[m.Face]
+	return Move{slice.Face, false, slice.Turns * m.Turns % 4, false}
+}
+
+//rotationCycles holds the faces whose contents move to the next face in the cycle for each rotation
+var rotationCycles = map[byte]string{
+	'x': "FUBD",
+	'y': "FLBR",
+	'z': "URDL",
+}
+
+//Rotation returns the whole cube rotation in the replacement of a move by face turns, it has no turns for a face turn
+func (m Move) Rotation() Move {
+	m = m.threeByThree()
+	r, ok := replacements[Move{m.Face, m.Wide, 1, false}]
+	if !ok {
+		return Move{}
+	}
+	return Move{r.rotation.Face, false, r.rotation.Turns * m.Turns % 4, false}
+}
+
+//Frame holds the face that is at each position after whole cube rotations
+type Frame map[byte]byte
+
+//NewFrame returns the frame of a cube that hasn't been rotated
+func NewFrame() Frame {
+	return Frame{'U': 'U', 'D': 'D', 'F': 'F', 'B': 'B', 'R': 'R', 'L': 'L'}
+}
+
+//Position returns the position face is at
+func (f Frame) Position(face byte) byte {
+	for p, x := range f {
+		if x == face {
+			return p
+		}
+	}
+	return 0
+}
+
+//Rotate updates the frame for a whole cube rotation
+func (f Frame) Rotate(rotation Move) {
+	cycle := rotationCycles[rotation.Face]
+	for turns := rotation.Turns; turns > 0; turns-- {
+		//the contents of each position move to the next so a position now holds what was at the one before it
+		last := f[cycle[3]]
+		for j := 3; j > 0; j-- {
+			f[cycle[j]] = f[cycle[j-1]]
+		}
+		f[cycle[0]] = last
+	}
+}
+
+//FaceTurns returns the sequence of a 3x3 with its wide moves, slices and rotations replaced by face turns.
+//The face turns leave the cube in the same state apart from a whole cube rotation, so a solution stays a solution.
+func (s Sequence) FaceTurns() Sequence {
+	frame := NewFrame()
+	result := Sequence{}
+	for _, m := range s {
+		m = m.threeByThree()
+		r, ok := replacements[Move{m.Face, m.Wide, 1, false}]
+		if !ok {
+			result = append(result, Move{frame[m.Face], false, m.Turns, false})
+			continue
+		}
+		for _, t := range r.turns {
+			result = append(result, Move{frame[t.Face], false, t.Turns * m.Turns % 4, false})
+		}
+		frame.Rotate(m.Rotation())
+	}
+	return result
+}
@@ -0,0 +1,118 @@
+package notation
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	data := []struct {
+		s        string
+		expected string
+	}{
+		{"R U R' U'", "R U R' U'"},
+		{"RUR'U'", "R U R' U'"},
+		{"  R   U\tF2\n", "R U F2"},
+		{"R2' R3 R4 R5'", "R2 R' R'"},
+		{"Rw r Uw' u2 Lw2'", "Rw Rw Uw' Uw2 Lw2"},
+		{"M E' S2 x y' z2", "M E' S2 x y' z2"},
+		{"2R 2L' 2U2 2F3 R2R", "2R 2L' 2U2 2F' R2 R"},
+		{"(R U R' U')2", "R U R' U' R U R' U'"},
+		{"(R U)' F", "U' R' F"},
+		{"((R U)2 F)2'", "F' U' R' U' R' F' U' R' U' R'"},
+		{"R // a comment\nU // another", "R U"},
+		{"R U’ F", "R U' F"},
+		{"[R: U]", "R U R'"},
+		{"[R, U]", "R U R' U'"},
+		{"[R U: F2]", "R U F2 U' R'"},
+		{"[R, U]2", "R U R' U' R U R' U'"},
+		{"[R: U]'", "R U' R'"},
+		{"[F: [R, U]]", "F R U R' U' F'"},
+		{"[(R U)2, D]", "R U R U D U' R' U' R' D'"},
+		{"", ""},
+		{"()3", ""},
+	}
+	for _, x := range data {
+		seq, err := Parse(x.s)
+		if err != nil {
+			t.Error("Failed Parse for ", x.s, " got error: ", err)
+			continue
+		}
+		if seq.String() != x.expected {
+			t.Error("Failed Parse for ", x.s, " got: ", seq.String(), " expected: ", x.expected)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	data := []struct {
+		s      string
+		err    error
+		offset int
+	}{
+		{"R U Q", ErrUnknownMove, 4},
+		{"R Uw w", ErrUnknownMove, 5},
+		{"R (U F", ErrUnclosedGroup, 2},
+		{"R U) F", ErrUnexpectedClose, 3},
+		{"R0 U", ErrBadCount, 1},
+		{"(R U)0", ErrBadCount, 5},
+		{"(R U)1001", ErrBadCount, 5},
+		{"R99999999999999999999", ErrBadCount, 1},
+		{"R, U", ErrUnexpectedClose, 1},
+		{"[R U]", ErrBadBracket, 4},
+		{"[R: U: F]", ErrBadBracket, 5},
+		{"[R, U", ErrUnclosedGroup, 0},
+		{"(R, U)", ErrUnexpectedClose, 2},
+		{"(R U]", ErrUnexpectedClose, 4},
+		{"(((R)1000)1000)1000", ErrTooLong, 10},
+		{"R 2Rw", ErrUnknownMove, 2},
+		{"2r", ErrUnknownMove, 0},
+		{"U 2M", ErrUnknownMove, 2},
+	}
+	for _, x := range data {
+		_, err := Parse(x.s)
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Error("Failed Parse for ", x.s, " got: ", err, " expected a *ParseError")
+			continue
+		}
+		if !errors.Is(err, x.err) || pe.Offset != x.offset {
+			t.Error("Failed Parse for ", x.s, " got: ", err, " expected: ", &ParseError{x.offset, x.err})
+		}
+	}
+}
+
+func TestInverse(t *testing.T) {
+	seq := MustParse("R U2 Fw' M x")
+	seq = append(seq, MustParse("2B")...)
+	if seq.Inverse().String() != "2B' x' M' Fw U2 R'" {
+		t.Error("Failed Inverse got: ", seq.Inverse().String())
+	}
+}
+
+func TestFaceTurns(t *testing.T) {
+	data := []struct {
+		s        string
+		expected string
+	}{
+		{"R U' F2", "R U' F2"},
+		{"Rw", "L"},
+		{"Rw U", "L F"},
+		{"x U", "F"},
+		{"x2 U", "D"},
+		{"y R", "B"},
+		{"z' U", "R"},
+		{"M U", "R L' B"},
+		{"Uw2 R", "D2 L"},
+		{"x y R", "U"},
+		{"2R", "R' L"},
+		{"2R U", "R' L F"},
+		{"2F' 2D2", "F B' R2 L2"},
+	}
+	for _, x := range data {
+		got := MustParse(x.s).FaceTurns().String()
+		if got != x.expected {
+			t.Error("Failed FaceTurns for ", x.s, " got: ", got, " expected: ", x.expected)
+		}
+	}
+}
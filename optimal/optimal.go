@@ -0,0 +1,648 @@
+package optimal
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"github.com/davidafox/rubikscubesolver/algorithm"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/cubie"
+	"github.com/davidafox/rubikscubesolver/solver"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//optimal finds the shortest solution using iterative deepening A* search.
+//The search is guided by pattern databases, tables holding the number of
+//moves needed to solve a subset of the cubies from every arrangement of that
+//subset.  Since solving the whole cube solves any subset the table values
+//never overestimate and the first solution found is a shortest one.
+
+var ErrNotTable = errors.New("The file is not a pattern database")
+var ErrTableVersion = errors.New("The pattern database was written by an unsupported version")
+var ErrNoTables = errors.New("At least one pattern database is needed")
+var ErrNoSolution = errors.New("No solution was found within the maximum length")
+
+const TABLE_VERSION = 1
+const MAX_LENGTH = 20
+
+var tableMagic = [4]byte{'R', 'C', 'P', 'D'}
+
+//unvisited marks table entries the breadth first search has not reached yet
+const unvisited = 0xF
+
+//Table is a pattern database for the cubies listed in corners and edges
+type Table struct {
+	corners []int
+	edges   []int
+	//number of values for each part of the index
+	cornerPerms   int
+	cornerOrients int
+	edgePerms     int
+	edgeOrients   int
+	size          int
+	//two distances per byte, the low nibble holds the even index
+	data []byte
+}
+
+//pattern is the positions and orientations of the tracked cubies in the order they are listed in the table
+type pattern struct {
+	corners      int
+	edges        int
+	cornerPos    [8]int
+	cornerOrient [8]int
+	edgePos      [12]int
+	edgeOrient   [12]int
+}
+
+//where each position's cubie goes and how much it turns for each move
+var cornerDest, cornerTwist [cubie.N_MOVE][8]int
+var edgeDest, edgeFlip [cubie.N_MOVE][12]int
+
+func init() {
+	for m := 0; m < cubie.N_MOVE; m++ {
+		c := cubie.NewMoveCube(m)
+		for i := 0; i < 8; i++ {
+			cornerDest[m][c.CornerPerm[i]] = i
+			cornerTwist[m][c.CornerPerm[i]] = c.CornerOrient[i]
+		}
+		for i := 0; i < 12; i++ {
+			edgeDest[m][c.EdgePerm[i]] = i
+			edgeFlip[m][c.EdgePerm[i]] = c.EdgeOrient[i]
+		}
+	}
+}
+
+//NewTable returns an empty table for the listed corner and edge cubies, Generate must be called before it is used
+func NewTable(corners, edges []int) *Table {
+	t := new(Table)
+	t.corners = append([]int{}, corners...)
+	t.edges = append([]int{}, edges...)
+	t.cornerPerms = arrangements(8, len(corners))
+	t.cornerOrients = orientations(3, 8, len(corners))
+	t.edgePerms = arrangements(12, len(edges))
+	t.edgeOrients = orientations(2, 12, len(edges))
+	t.size = t.cornerPerms * t.cornerOrients * t.edgePerms * t.edgeOrients
+	t.data = make([]byte, (t.size+1)/2)
+	return t
+}
+
+//NewCornerTable returns an empty table for all eight corners
+func NewCornerTable() *Table {
+	return NewTable([]int{cubie.URF, cubie.UFL, cubie.ULB, cubie.UBR, cubie.DFR, cubie.DLF, cubie.DBL, cubie.DRB}, nil)
+}
+
+//NewEdgeTable returns an empty table for the listed edges
+func NewEdgeTable(edges []int) *Table {
+	return NewTable(nil, edges)
+}
+
+//DefaultTables generates the corner table and two tables of six edges each, this takes a few minutes and about 86MB, 44MB for the corners and 21MB for each edge table at two entries a byte
+func DefaultTables(ctx context.Context) ([]*Table, error) {
+	tables := defaultTables()
+	for _, t := range tables {
+		if err := t.Generate(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+func defaultTables() []*Table {
+	return []*Table{
+		NewCornerTable(),
+		NewEdgeTable([]int{cubie.UR, cubie.UF, cubie.UL, cubie.UB, cubie.DR, cubie.DF}),
+		NewEdgeTable([]int{cubie.DL, cubie.DB, cubie.FR, cubie.FL, cubie.BL, cubie.BR}),
+	}
+}
+
+//defaultTableFiles holds the file names of the default tables in a table directory
+var defaultTableFiles = []string{"corners.rcpd", "edges1.rcpd", "edges2.rcpd"}
+
+//loadedTables keeps the default tables of each directory so they are only loaded or generated once, lock is held
+//while they are so waiting for it can be given up when a context is done
+var loadedTables = struct {
+	lock chan struct{}
+	dirs map[string][]*Table
+}{lock: make(chan struct{}, 1), dirs: make(map[string][]*Table)}
+
+//LoadDefaultTables loads the default tables from dir, generating and saving any that are missing. An empty dir
+//generates the tables without saving them. It returns a *solver.CancelError when ctx is done first, tables already
+//saved are kept for the next call.
+func LoadDefaultTables(ctx context.Context, dir string) ([]*Table, error) {
+	select {
+	case loadedTables.lock <- struct{}{}:
+	case <-ctx.Done():
+		return nil, &solver.CancelError{Err: ctx.Err()}
+	}
+	defer func() { <-loadedTables.lock }()
+	if tables, ok := loadedTables.dirs[dir]; ok {
+		return tables, nil
+	}
+	tables := defaultTables()
+	for i, t := range tables {
+		if dir == "" {
+			if err := t.Generate(ctx); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		path := filepath.Join(dir, defaultTableFiles[i])
+		loaded, err := LoadTable(path)
+		if err == nil {
+			tables[i] = loaded
+			continue
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err := t.Generate(ctx); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+		if err := t.Save(path); err != nil {
+			return nil, err
+		}
+	}
+	loadedTables.dirs[dir] = tables
+	return tables, nil
+}
+
+//arrangements is the number of ways to place k cubies in n positions
+func arrangements(n, k int) int {
+	r := 1
+	for i := 0; i < k; i++ {
+		r *= n - i
+	}
+	return r
+}
+
+//orientations is the number of ways to turn k of the n cubies, when all n are tracked the last one's orientation is fixed by the others
+func orientations(ways, n, k int) int {
+	if k == n {
+		k--
+	}
+	r := 1
+	for i := 0; i < k; i++ {
+		r *= ways
+	}
+	return r
+}
+
+func (t *Table) Size() int {
+	return t.size
+}
+
+func (t *Table) get(i int) int {
+	return int(t.data[i/2]>>(4*uint(i%2))) & 0xF
+}
+
+func (t *Table) set(i, v int) {
+	shift := 4 * uint(i%2)
+	t.data[i/2] = t.data[i/2]&^(0xF<<shift) | byte(v)<<shift
+}
+
+//Generate fills in the table with a breadth first search from the solved cube. The entries at each depth are kept in a
+//list while there are few of them so the table is only scanned for the larger depths, and once more than half the
+//table is found the scan looks for the entries left instead, an entry is one deeper when a move takes it to the depth
+//just finished. It returns a *solver.CancelError when ctx is canceled or its deadline passes.
+func (t *Table) Generate(ctx context.Context) error {
+	for i := range t.data {
+		t.data[i] = 0xFF
+	}
+	solved := t.solvedPattern()
+	start := t.patternIndex(&solved)
+	t.set(start, 0)
+	frontier := []int32{int32(start)}
+	maxFrontier := t.size / 32
+	found := 1
+	for depth := 0; found < t.size; depth++ {
+		var next []int32
+		if frontier != nil {
+			next = []int32{}
+		}
+		added := 0
+		//expand sets the unvisited entries one move from entry i to the next depth
+		expand := func(i int) {
+			p := t.decode(i)
+			for m := 0; m < cubie.N_MOVE; m++ {
+				moved := p.move(m)
+				j := t.patternIndex(&moved)
+				if t.get(j) == unvisited {
+					t.set(j, depth+1)
+					added++
+					if next != nil && len(next) < maxFrontier {
+						next = append(next, int32(j))
+					} else {
+						next = nil
+					}
+				}
+			}
+		}
+		switch {
+		case frontier != nil:
+			for n, i := range frontier {
+				if n%(1<<16) == 0 && ctx.Err() != nil {
+					return &solver.CancelError{Err: ctx.Err()}
+				}
+				expand(int(i))
+			}
+		case found > t.size/2:
+			for i := 0; i < t.size; i++ {
+				if i%(1<<16) == 0 && ctx.Err() != nil {
+					return &solver.CancelError{Err: ctx.Err()}
+				}
+				if t.get(i) != unvisited {
+					continue
+				}
+				p := t.decode(i)
+				for m := 0; m < cubie.N_MOVE; m++ {
+					moved := p.move(m)
+					if t.get(t.patternIndex(&moved)) == depth {
+						t.set(i, depth+1)
+						added++
+						break
+					}
+				}
+			}
+		default:
+			for i := 0; i < t.size; i++ {
+				if i%(1<<16) == 0 && ctx.Err() != nil {
+					return &solver.CancelError{Err: ctx.Err()}
+				}
+				if t.get(i) == depth {
+					expand(i)
+				}
+			}
+		}
+		if added == 0 {
+			break
+		}
+		frontier = next
+		found += added
+	}
+	return nil
+}
+
+//Distance returns the number of moves needed to solve the table's cubies
+func (t *Table) Distance(c *cubie.Cube) int {
+	p := t.pattern(c)
+	return t.get(t.patternIndex(&p))
+}
+
+func (t *Table) solvedPattern() pattern {
+	return t.pattern(cubie.NewCube())
+}
+
+func (t *Table) pattern(c *cubie.Cube) pattern {
+	var cornerAt [8]int
+	var edgeAt [12]int
+	for i, x := range c.CornerPerm {
+		cornerAt[x] = i
+	}
+	for i, x := range c.EdgePerm {
+		edgeAt[x] = i
+	}
+	p := pattern{corners: len(t.corners), edges: len(t.edges)}
+	for i, x := range t.corners {
+		p.cornerPos[i] = cornerAt[x]
+		p.cornerOrient[i] = c.CornerOrient[cornerAt[x]]
+	}
+	for i, x := range t.edges {
+		p.edgePos[i] = edgeAt[x]
+		p.edgeOrient[i] = c.EdgeOrient[edgeAt[x]]
+	}
+	return p
+}
+
+func (p *pattern) move(m int) pattern {
+	r := pattern{corners: p.corners, edges: p.edges}
+	for i, x := range p.cornerPos[:p.corners] {
+		r.cornerPos[i] = cornerDest[m][x]
+		r.cornerOrient[i] = (p.cornerOrient[i] + cornerTwist[m][x]) % 3
+	}
+	for i, x := range p.edgePos[:p.edges] {
+		r.edgePos[i] = edgeDest[m][x]
+		r.edgeOrient[i] = (p.edgeOrient[i] + edgeFlip[m][x]) % 2
+	}
+	return r
+}
+
+func (t *Table) patternIndex(p *pattern) int {
+	idx := positionIndex(p.cornerPos[:p.corners], 8)
+	idx = idx*t.cornerOrients + orientIndex(p.cornerOrient[:p.corners], 3, 8)
+	idx = idx*t.edgePerms + positionIndex(p.edgePos[:p.edges], 12)
+	idx = idx*t.edgeOrients + orientIndex(p.edgeOrient[:p.edges], 2, 12)
+	return idx
+}
+
+func (t *Table) decode(idx int) pattern {
+	p := pattern{corners: len(t.corners), edges: len(t.edges)}
+	decodeOrient(p.edgeOrient[:p.edges], idx%t.edgeOrients, 2, 12)
+	idx /= t.edgeOrients
+	decodePosition(p.edgePos[:p.edges], idx%t.edgePerms, 12)
+	idx /= t.edgePerms
+	decodeOrient(p.cornerOrient[:p.corners], idx%t.cornerOrients, 3, 8)
+	idx /= t.cornerOrients
+	decodePosition(p.cornerPos[:p.corners], idx, 8)
+	return p
+}
+
+//positionIndex numbers the ways of placing len(pos) cubies in n positions
+func positionIndex(pos []int, n int) int {
+	idx := 0
+	used := 0
+	for i, x := range pos {
+		rank := 0
+		for j := 0; j < x; j++ {
+			if used&(1<<uint(j)) == 0 {
+				rank++
+			}
+		}
+		used |= 1 << uint(x)
+		idx = idx*(n-i) + rank
+	}
+	return idx
+}
+
+//decodePosition fills pos with the placement numbered idx
+func decodePosition(pos []int, idx, n int) {
+	var ranks [12]int
+	k := len(pos)
+	for i := k - 1; i >= 0; i-- {
+		ranks[i] = idx % (n - i)
+		idx /= n - i
+	}
+	used := 0
+	for i, rank := range ranks[:k] {
+		for j := 0; j < n; j++ {
+			if used&(1<<uint(j)) != 0 {
+				continue
+			}
+			if rank == 0 {
+				pos[i] = j
+				used |= 1 << uint(j)
+				break
+			}
+			rank--
+		}
+	}
+}
+
+func orientIndex(orient []int, ways, n int) int {
+	digits := len(orient)
+	//when every cubie is tracked the last orientation is left out
+	if digits == n {
+		digits--
+	}
+	idx := 0
+	for _, x := range orient[:digits] {
+		idx = idx*ways + x
+	}
+	return idx
+}
+
+//decodeOrient fills orient with the orientations numbered idx
+func decodeOrient(orient []int, idx, ways, n int) {
+	k := len(orient)
+	sum := 0
+	digits := k
+	if k == n {
+		digits--
+	}
+	for i := digits - 1; i >= 0; i-- {
+		orient[i] = idx % ways
+		sum += orient[i]
+		idx /= ways
+	}
+	if k == n {
+		orient[k-1] = (ways - sum%ways) % ways
+	}
+}
+
+//Write saves the table in a versioned binary format
+func (t *Table) Write(w io.Writer) error {
+	header := []byte{tableMagic[0], tableMagic[1], tableMagic[2], tableMagic[3]}
+	header = binary.LittleEndian.AppendUint16(header, TABLE_VERSION)
+	header = append(header, byte(len(t.corners)))
+	for _, x := range t.corners {
+		header = append(header, byte(x))
+	}
+	header = append(header, byte(len(t.edges)))
+	for _, x := range t.edges {
+		header = append(header, byte(x))
+	}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(t.data)
+	return err
+}
+
+//ReadTable loads a table saved by Write
+func ReadTable(r io.Reader) (*Table, error) {
+	header := make([]byte, 7)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, ErrNotTable
+	}
+	if header[0] != tableMagic[0] || header[1] != tableMagic[1] || header[2] != tableMagic[2] || header[3] != tableMagic[3] {
+		return nil, ErrNotTable
+	}
+	if binary.LittleEndian.Uint16(header[4:6]) != TABLE_VERSION {
+		return nil, ErrTableVersion
+	}
+	corners, err := readPieces(r, int(header[6]), 8)
+	if err != nil {
+		return nil, err
+	}
+	count := make([]byte, 1)
+	if _, err := io.ReadFull(r, count); err != nil {
+		return nil, ErrNotTable
+	}
+	edges, err := readPieces(r, int(count[0]), 12)
+	if err != nil {
+		return nil, err
+	}
+	t := NewTable(corners, edges)
+	if _, err := io.ReadFull(r, t.data); err != nil {
+		return nil, ErrNotTable
+	}
+	return t, nil
+}
+
+func readPieces(r io.Reader, k, n int) ([]int, error) {
+	if k > n {
+		return nil, ErrNotTable
+	}
+	b := make([]byte, k)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, ErrNotTable
+	}
+	pieces := make([]int, k)
+	for i, x := range b {
+		if int(x) >= n {
+			return nil, ErrNotTable
+		}
+		pieces[i] = int(x)
+	}
+	return pieces, nil
+}
+
+//Save writes the table to the file at path
+func (t *Table) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if err := t.Write(w); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+//LoadTable reads a table saved with Save
+func LoadTable(path string) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadTable(bufio.NewReader(f))
+}
+
+func init() {
+	solver.Register("optimal", func(ctx context.Context, c *bytecube.Cube, o solver.Options) (solver.Solver, error) {
+		tables, err := LoadDefaultTables(ctx, o.TableDir)
+		if err != nil {
+			return nil, err
+		}
+		s, err := NewSolver(c, o.Target, tables)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	})
+}
+
+type Solver struct {
+	cube      *cubie.Cube
+	tables    []*Table
+	maxLength int
+	moves     []int
+	stop      *solver.Stopper
+	observer  solver.Observer
+}
+
+//NewSolver returns a solver of the shortest moves from c to target, or to the solved cube when target is "", using the
+//given generated tables as its heuristic
+func NewSolver(c *bytecube.Cube, target string, tables []*Table) (*Solver, error) {
+	if len(tables) == 0 {
+		return nil, ErrNoTables
+	}
+	if target != "" {
+		relative, err := solver.Relative(c, target)
+		if err != nil {
+			return nil, err
+		}
+		c = relative
+	}
+	cc, err := cubie.FromBytecube(c)
+	if err != nil {
+		return nil, err
+	}
+	if err = cc.Verify(); err != nil {
+		return nil, err
+	}
+	s := new(Solver)
+	s.cube = cc
+	s.tables = tables
+	s.maxLength = MAX_LENGTH
+	return s, nil
+}
+
+//SetObserver sets the observer that receives the progress of the solve, by default nothing is reported
+func (s *Solver) SetObserver(o solver.Observer) {
+	s.observer = o
+}
+
+//Solve returns a shortest solution in official notation
+func (s *Solver) Solve() (string, error) {
+	return s.SolveContext(context.Background())
+}
+
+//SolveContext stops the search and returns a *solver.CancelError when ctx is canceled or its deadline passes
+func (s *Solver) SolveContext(ctx context.Context) (string, error) {
+	s.stop = solver.NewStopper(ctx)
+	s.moves = make([]int, s.maxLength)
+	for depth := s.distance(s.cube); depth <= s.maxLength; depth++ {
+		s.observer.Notify(solver.Event{Kind: solver.DepthStarted, Depth: depth})
+		found := s.search(s.cube, 0, depth)
+		if err := s.stop.Err(); err != nil {
+			return "", err
+		}
+		if found {
+			steps := make([]string, depth)
+			for i, m := range s.moves[:depth] {
+				steps[i] = cubie.MoveName(m)
+			}
+			solution, err := algorithm.SimplifyString(strings.Join(steps, " "))
+			if err != nil {
+				return "", err
+			}
+			s.observer.Notify(solver.Event{Kind: solver.SolutionFound, Depth: depth, Solution: solution})
+			return solution, nil
+		}
+	}
+	return "", ErrNoSolution
+}
+
+//distance is the largest of the table distances
+func (s *Solver) distance(c *cubie.Cube) int {
+	d := 0
+	for _, t := range s.tables {
+		if x := t.Distance(c); x > d {
+			d = x
+		}
+	}
+	return d
+}
+
+func (s *Solver) search(c *cubie.Cube, depth, maxDepth int) bool {
+	if s.stop.Stopped() {
+		return false
+	}
+	if depth == maxDepth {
+		return c.Solved()
+	}
+	if s.distance(c) > maxDepth-depth {
+		return false
+	}
+	for m := 0; m < cubie.N_MOVE; m++ {
+		if depth > 0 && skipMove(s.moves[depth-1], m) {
+			continue
+		}
+		s.moves[depth] = m
+		next := *c
+		next.Move(m)
+		if s.search(&next, depth+1, maxDepth) {
+			return true
+		}
+	}
+	return false
+}
+
+//skipMove is true for turning the same face twice in a row and for turning opposite faces in the non-canonical order
+func skipMove(last, m int) bool {
+	return last/3 == m/3 || last/3 == m/3+3
+}
@@ -0,0 +1,225 @@
+package optimal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/cubie"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"github.com/davidafox/rubikscubesolver/solver"
+	"strings"
+	"testing"
+	"time"
+)
+
+var solvedCube = "000000000111111111222222222333333333444444444555555555"
+
+var tables []*Table
+
+//small tables that generate quickly
+func testTables() []*Table {
+	if tables != nil {
+		return tables
+	}
+	tables = []*Table{
+		NewTable([]int{cubie.URF, cubie.UFL, cubie.ULB, cubie.UBR}, nil),
+		NewTable([]int{cubie.DFR, cubie.DLF, cubie.DBL, cubie.DRB}, nil),
+		NewEdgeTable([]int{cubie.UR, cubie.UF, cubie.UL, cubie.UB}),
+		NewEdgeTable([]int{cubie.FR, cubie.FL, cubie.BL, cubie.BR}),
+	}
+	for _, t := range tables {
+		t.Generate(context.Background())
+	}
+	return tables
+}
+
+func TestPositionIndex(t *testing.T) {
+	for k := 0; k <= 4; k++ {
+		for i := 0; i < arrangements(12, k); i++ {
+			pos := make([]int, k)
+			decodePosition(pos, i, 12)
+			if positionIndex(pos, 12) != i {
+				t.Error("Failed positionIndex got: ", positionIndex(pos, 12), " expected: ", i)
+			}
+		}
+	}
+}
+
+func TestPatternIndex(t *testing.T) {
+	tables := []*Table{
+		NewCornerTable(),
+		NewTable([]int{cubie.DBL, cubie.URF}, []int{cubie.BR, cubie.UF, cubie.DL}),
+	}
+	for _, table := range tables {
+		for i := 0; i < table.Size(); i += 9973 {
+			p := table.decode(i)
+			if table.patternIndex(&p) != i {
+				t.Error("Failed patternIndex got: ", table.patternIndex(&p), " expected: ", i)
+			}
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tables := testTables()
+	data := []string{
+		"",
+		"R",
+		"R U",
+		"R U F' L2",
+		"R U' B' L F R' U2 F2 L' D R U L'",
+	}
+	for _, x := range data {
+		c := cubie.NewCube()
+		n := len(strings.Fields(x))
+		for _, step := range strings.Fields(x) {
+			c.Move(moveNumber(step))
+		}
+		for _, table := range tables {
+			if d := table.Distance(c); d > n {
+				t.Error("Failed Distance for ", x, " got: ", d, " more than ", n)
+			}
+		}
+	}
+	if tables[0].Distance(cubie.NewMoveCube(3)) != 1 {
+		t.Error("Failed Distance for R got: ", tables[0].Distance(cubie.NewMoveCube(3)), " expected: 1")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	//the list, the scan and the backward scan give the distances of a search that scans the table at every depth
+	table := NewTable([]int{cubie.URF, cubie.DBL, cubie.DRB}, []int{cubie.UF, cubie.BL})
+	if err := table.Generate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	expected := make([]int, table.Size())
+	for i := range expected {
+		expected[i] = unvisited
+	}
+	solved := table.solvedPattern()
+	expected[table.patternIndex(&solved)] = 0
+	for depth, added := 0, 1; added > 0; depth++ {
+		added = 0
+		for i, d := range expected {
+			if d != depth {
+				continue
+			}
+			p := table.decode(i)
+			for m := 0; m < cubie.N_MOVE; m++ {
+				next := p.move(m)
+				if j := table.patternIndex(&next); expected[j] == unvisited {
+					expected[j] = depth + 1
+					added++
+				}
+			}
+		}
+	}
+	for i, d := range expected {
+		if table.get(i) != d {
+			t.Fatal("Failed Generate entry ", i, " got: ", table.get(i), " expected: ", d)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := NewCornerTable().Generate(ctx)
+	var cancelErr *solver.CancelError
+	if !errors.As(err, &cancelErr) || !errors.Is(err, context.Canceled) {
+		t.Error("Failed Generate got: ", err, " expected: ", context.Canceled)
+	}
+	if _, err := LoadDefaultTables(ctx, t.TempDir()); !errors.As(err, &cancelErr) {
+		t.Error("Failed LoadDefaultTables got: ", err, " expected: ", context.Canceled)
+	}
+}
+
+func moveNumber(step string) int {
+	for m := 0; m < cubie.N_MOVE; m++ {
+		if cubie.MoveName(m) == step {
+			return m
+		}
+	}
+	return -1
+}
+
+func TestWriteAndReadTable(t *testing.T) {
+	table := NewTable([]int{cubie.URF, cubie.DBL}, []int{cubie.UF})
+	table.Generate(context.Background())
+	var b bytes.Buffer
+	if err := table.Write(&b); err != nil {
+		t.Error("Failed Write got error: ", err)
+	}
+	data := b.Bytes()
+	read, err := ReadTable(bytes.NewReader(data))
+	if err != nil {
+		t.Error("Failed ReadTable got error: ", err)
+		return
+	}
+	if !bytes.Equal(read.data, table.data) || read.Size() != table.Size() {
+		t.Error("Failed ReadTable tables differ")
+	}
+	bad := append([]byte{}, data...)
+	bad[4] = 2
+	if _, err := ReadTable(bytes.NewReader(bad)); err != ErrTableVersion {
+		t.Error("Failed ReadTable got: ", err, " expected: ", ErrTableVersion)
+	}
+	if _, err := ReadTable(strings.NewReader("not a table")); err != ErrNotTable {
+		t.Error("Failed ReadTable got: ", err, " expected: ", ErrNotTable)
+	}
+	if _, err := ReadTable(bytes.NewReader(data[:len(data)-1])); err != ErrNotTable {
+		t.Error("Failed ReadTable got: ", err, " expected: ", ErrNotTable)
+	}
+}
+
+func TestSolve(t *testing.T) {
+	tables := testTables()
+	data := []struct {
+		steps  string
+		length int
+	}{
+		{"", 0},
+		{"R", 1},
+		{"R U", 2},
+		{"R U R' U'", 4},
+		{"F R B L U2", 5},
+		{"R2 U L U2 R' D", 6},
+	}
+	for _, x := range data {
+		c, _ := bytecube.NewCube(solvedCube)
+		r := rubikscuberunner.NewOfficialRunner(c)
+		if x.steps != "" {
+			r.Run(x.steps)
+		}
+		s, err := NewSolver(c, "", tables)
+		if err != nil {
+			t.Error("Failed NewSolver for ", x.steps, " got error: ", err)
+			continue
+		}
+		result, err := s.Solve()
+		if err != nil {
+			t.Error("Failed Solve for ", x.steps, " got error: ", err)
+			continue
+		}
+		if len(strings.Fields(result)) != x.length {
+			t.Error("Failed Solve for ", x.steps, " got: ", result, " expected length: ", x.length)
+		}
+		if result != "" {
+			r.Run(result)
+		}
+		if !c.Solved() {
+			t.Error("Failed to solve ", x.steps, " with ", result, " got: ", c.String())
+		}
+	}
+}
+
+func TestSolveContext(t *testing.T) {
+	c, _ := bytecube.NewCube(solvedCube)
+	rubikscuberunner.NewOfficialRunner(c).Run("D2 R' F2 U B2 L' D' F R2 U2 B L2 F' D R' U' L B2 F D'")
+	s, _ := NewSolver(c, "", testTables())
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	result, err := s.SolveContext(ctx)
+	var cancelErr *solver.CancelError
+	if !errors.As(err, &cancelErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("Failed SolveContext got: ", result, err, " expected: ", context.DeadlineExceeded)
+	}
+}
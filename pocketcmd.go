@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/davidafox/rubikscubesolver/pocketcube"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"os"
+	"strings"
+)
+
+//runPocket solves a 2x2x2 cube, it is run with: rubikscubesolver pocket [-state state | -scramble moves] [-tables dir]
+func runPocket(args []string) int {
+	flags := flag.NewFlagSet("pocket", flag.ExitOnError)
+	state := flags.String("state", "", "the 24 digit state to solve")
+	scrambleMoves := flags.String("scramble", "", "moves applied to a solved cube to give the state to solve instead of -state")
+	tables := flags.String("tables", "", "directory the distance table is loaded from and saved to")
+	flags.Parse(args)
+	var c *pocketcube.Cube
+	var err error
+	switch {
+	case *state != "" && *scrambleMoves != "":
+		fmt.Fprintln(os.Stderr, "Give -state or -scramble, not both")
+		return EXIT_INVALID_INPUT
+	case *scrambleMoves != "":
+		c, _ = pocketcube.NewCube("000011112222333344445555")
+		if err = rubikscuberunner.NewOfficialRunner(c).Run(*scrambleMoves); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return EXIT_INVALID_INPUT
+		}
+	default:
+		if c, err = pocketcube.NewCube(strings.TrimSpace(*state)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return EXIT_INVALID_INPUT
+		}
+	}
+	table, err := pocketcube.LoadDefaultTable(*tables)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return EXIT_ERROR
+	}
+	s, err := pocketcube.NewSolver(c, table)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return EXIT_UNSOLVABLE
+	}
+	solution, err := s.Solve()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return EXIT_ERROR
+	}
+	fmt.Println(solution)
+	return EXIT_OK
+}
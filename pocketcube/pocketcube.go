@@ -0,0 +1,389 @@
+package pocketcube
+
+import (
+	"errors"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+)
+
+//pocketcube is the 2x2x2 cube. Its sides are in the same order and layout as bytecube's with four stickers each,
+//so a state is 24 digits starting with the front's top left sticker. With no centers the color of each face
+//is given by the stickers, and a cube is solved when every side is one color however it is held.
+
+var ErrIncorrectNumber = errors.New("The cube must have 24 stickers")
+var ErrIncorrectColorNumbers = errors.New("There must be 4 of each color")
+
+//N_STICKER is the number of stickers of the cube
+const N_STICKER = 24
+
+//corner positions
+const (
+	URF = iota
+	UFL
+	ULB
+	UBR
+	DFR
+	DLF
+	DBL
+	DRB
+)
+
+//location of a sticker as bytecube side and spot
+type facelet struct {
+	side int
+	spot int
+}
+
+func (f facelet) index() int {
+	return f.side*4 + f.spot
+}
+
+//the stickers of each corner position starting with the U or D sticker and going clockwise
+var cornerFacelet = [8][3]facelet{
+	{{4, 3}, {3, 0}, {0, 1}},
+	{{4, 2}, {0, 0}, {1, 1}},
+	{{4, 0}, {1, 0}, {2, 1}},
+	{{4, 1}, {2, 0}, {3, 1}},
+	{{5, 1}, {0, 3}, {3, 2}},
+	{{5, 0}, {1, 3}, {0, 2}},
+	{{5, 2}, {2, 3}, {1, 2}},
+	{{5, 3}, {3, 3}, {2, 2}},
+}
+
+//turns holds for each side the sticker moved to each place by a clockwise turn of that side
+var turns [6][N_STICKER]uint8
+
+func init() {
+	positions := make(map[[3]int]int)
+	for i := 0; i < N_STICKER; i++ {
+		positions[stickerPosition(i)] = i
+	}
+	for side := 0; side < 6; side++ {
+		//halving the position of spot 0 leaves the side's outward direction,
+		//a clockwise turn seen from outside is a quarter turn back around it
+		n := stickerPosition(side * 4)
+		for axis := range n {
+			n[axis] /= 2
+		}
+		for i := 0; i < N_STICKER; i++ {
+			p := stickerPosition(i)
+			if dot(n, p) <= 0 {
+				turns[side][i] = uint8(i)
+				continue
+			}
+			//p is turned to n(n.p) - n x p
+			c := cross(n, p)
+			var moved [3]int
+			for axis := range moved {
+				moved[axis] = n[axis]*dot(n, p) - c[axis]
+			}
+			turns[side][positions[moved]] = uint8(i)
+		}
+	}
+}
+
+//stickerPosition returns the position of a sticker with the cube's center at 0, the stickers' centers at 1 and the sides at 2.
+//The front is toward positive z, the right toward positive x and the top toward positive y.
+func stickerPosition(i int) [3]int {
+	side, r, c := i/4, i%4/2, i%2
+	switch side {
+	case 0:
+		return [3]int{-1 + 2*c, 1 - 2*r, 2}
+	case 1:
+		return [3]int{-2, 1 - 2*r, -1 + 2*c}
+	case 2:
+		return [3]int{1 - 2*c, 1 - 2*r, -2}
+	case 3:
+		return [3]int{2, 1 - 2*r, 1 - 2*c}
+	case 4:
+		return [3]int{-1 + 2*c, 2, -1 + 2*r}
+	}
+	return [3]int{-1 + 2*c, -2, 1 - 2*r}
+}
+
+func dot(a, b [3]int) int {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func cross(a, b [3]int) [3]int {
+	return [3]int{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+//State is the color of every sticker, it can be compared and used as a map key
+type State [N_STICKER]uint8
+
+//Cube is a 2x2x2 cube
+type Cube struct {
+	stickers State
+}
+
+//NewCube reads a state of 24 digits from 0 to 5, it is not validated
+func NewCube(s string) (*Cube, error) {
+	if len(s) != N_STICKER {
+		return nil, ErrIncorrectNumber
+	}
+	c := new(Cube)
+	for i := 0; i < N_STICKER; i++ {
+		if s[i] < '0' || s[i] > '5' {
+			return nil, bytecube.ErrInvalidColor
+		}
+		c.stickers[i] = s[i] - '0'
+	}
+	return c, nil
+}
+
+//NewValidCube reads a cube like NewCube but returns an error for anything other than a cube that can be solved
+func NewValidCube(s string) (*Cube, error) {
+	c, err := NewCube(s)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func NewWithState(s State) *Cube {
+	return &Cube{s}
+}
+
+func (c *Cube) State() State {
+	return c.stickers
+}
+
+func (c *Cube) String() string {
+	b := make([]byte, N_STICKER)
+	for i, v := range c.stickers {
+		b[i] = '0' + v
+	}
+	return string(b)
+}
+
+//Solved is true when every side is one color
+func (c *Cube) Solved() bool {
+	for side := 0; side < 6; side++ {
+		for spot := 1; spot < 4; spot++ {
+			if c.stickers[side*4+spot] != c.stickers[side*4] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+//Validate returns an error for a cube that can't be solved, like bytecube's Validate.
+//Any arrangement of the corners can be solved so there is no parity to check.
+func (c *Cube) Validate() (bool, error) {
+	if _, err := c.corners(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+//turn turns side clockwise the given number of quarter turns
+func (c *Cube) turn(side, quarters int) {
+	for ; quarters > 0; quarters-- {
+		var next State
+		for i, from := range turns[side] {
+			next[i] = c.stickers[from]
+		}
+		c.stickers = next
+	}
+}
+
+func (c *Cube) RotateF() {
+	c.turn(0, 1)
+}
+
+func (c *Cube) RotateFCounter() {
+	c.turn(0, 3)
+}
+
+func (c *Cube) RotateL() {
+	c.turn(1, 1)
+}
+
+func (c *Cube) RotateLCounter() {
+	c.turn(1, 3)
+}
+
+func (c *Cube) RotateB() {
+	c.turn(2, 1)
+}
+
+func (c *Cube) RotateBCounter() {
+	c.turn(2, 3)
+}
+
+func (c *Cube) RotateR() {
+	c.turn(3, 1)
+}
+
+func (c *Cube) RotateRCounter() {
+	c.turn(3, 3)
+}
+
+func (c *Cube) RotateU() {
+	c.turn(4, 1)
+}
+
+func (c *Cube) RotateUCounter() {
+	c.turn(4, 3)
+}
+
+func (c *Cube) RotateD() {
+	c.turn(5, 1)
+}
+
+func (c *Cube) RotateDCounter() {
+	c.turn(5, 3)
+}
+
+//corners is a cube as the corner in each position and how many clockwise twists its U or D sticker is from the U or D side.
+//The corners are numbered by their solved positions with the DBL corner taken as solved, since it is never moved by the solver.
+type corners struct {
+	perm  [8]int8
+	twist [8]int8
+}
+
+//FaceColors returns the color each side has once c is solved without turning the cube, when the DBL corner is in place
+func (c *Cube) FaceColors() ([6]int, error) {
+	if _, err := c.corners(); err != nil {
+		return [6]int{}, err
+	}
+	return c.faceColors()
+}
+
+//faceColors reads the colors of the sides from the DBL corner
+func (c *Cube) faceColors() ([6]int, error) {
+	var count [6]int
+	for _, v := range c.stickers {
+		count[v]++
+	}
+	for _, n := range count {
+		if n != 4 {
+			return [6]int{}, ErrIncorrectColorNumbers
+		}
+	}
+	//the colors that share a corner with each color, a color shares a corner with all but itself and its opposite
+	var neighbors [6][6]bool
+	for _, f := range cornerFacelet {
+		for _, a := range f {
+			for _, b := range f {
+				neighbors[c.stickers[a.index()]][c.stickers[b.index()]] = true
+			}
+		}
+	}
+	opposite := func(color uint8) (uint8, bool) {
+		found, n := uint8(0), 0
+		for x := uint8(0); x < 6; x++ {
+			if !neighbors[color][x] {
+				found = x
+				n++
+			}
+		}
+		return found, n == 1 && found != color
+	}
+	//the DBL corner gives the colors of its sides and the opposites give the others
+	var faceColor [6]int
+	var used [6]bool
+	for _, f := range cornerFacelet[DBL] {
+		color := c.stickers[f.index()]
+		other, ok := opposite(color)
+		if !ok || used[color] || used[other] {
+			return [6]int{}, bytecube.ErrIncorrectCorners
+		}
+		used[color], used[other] = true, true
+		faceColor[f.side] = int(color)
+		faceColor[oppositeSide[f.side]] = int(other)
+	}
+	return faceColor, nil
+}
+
+//corners reads the corners of the cube, returning an error when they don't make a cube that can be solved
+func (c *Cube) corners() (*corners, error) {
+	faceColor, err := c.faceColors()
+	if err != nil {
+		return nil, err
+	}
+	x := new(corners)
+	var placed [8]bool
+	twists := 0
+	for p, f := range cornerFacelet {
+		var colors [3]int
+		for i, sticker := range f {
+			colors[i] = int(c.stickers[sticker.index()])
+		}
+		home, twist, ok := identify(colors, faceColor)
+		if !ok || placed[home] {
+			return nil, bytecube.ErrIncorrectCorners
+		}
+		placed[home] = true
+		x.perm[p] = int8(home)
+		x.twist[p] = int8(twist)
+		twists += twist
+	}
+	if twists%3 != 0 {
+		return nil, bytecube.ErrTwistedCorner
+	}
+	return x, nil
+}
+
+//oppositeSide holds the side opposite each side
+var oppositeSide = [6]int{2, 3, 0, 1, 5, 4}
+
+//identify returns the solved position of the corner with colors listed from a position's U or D sticker clockwise,
+//and the number of twists from its U or D color to the position's U or D sticker
+func identify(colors [3]int, faceColor [6]int) (int, int, bool) {
+	for home, f := range cornerFacelet {
+		for twist := 0; twist < 3; twist++ {
+			match := true
+			for i := range colors {
+				if colors[(i+twist)%3] != faceColor[f[i].side] {
+					match = false
+				}
+			}
+			if match {
+				return home, twist, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+//move returns the corners after the clockwise quarter turn of side
+func (x *corners) move(side int) *corners {
+	next := new(corners)
+	for p := range x.perm {
+		q := cornerDest[side][p]
+		next.perm[q] = x.perm[p]
+		next.twist[q] = (x.twist[p] + cornerTwist[side][p]) % 3
+	}
+	return next
+}
+
+//cornerDest and cornerTwist hold where each position's corner goes and how far it is twisted by a clockwise turn of each side
+var cornerDest, cornerTwist [6][8]int8
+
+func init() {
+	//where each sticker is moved to by each turn
+	var dest [6][N_STICKER]int
+	for side := range turns {
+		for i, from := range turns[side] {
+			dest[side][from] = i
+		}
+	}
+	slot := make(map[int][2]int)
+	for p, f := range cornerFacelet {
+		for i, sticker := range f {
+			slot[sticker.index()] = [2]int{p, i}
+		}
+	}
+	for side := range turns {
+		for p, f := range cornerFacelet {
+			to := slot[dest[side][f[0].index()]]
+			cornerDest[side][p] = int8(to[0])
+			cornerTwist[side][p] = int8(to[1])
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package pocketcube
+
+import (
+	"bytes"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const SOLVED = "000011112222333344445555"
+
+//rotations holds the turns of a pocket cube and a 3x3 cube by the same letter
+var rotations = map[string]struct {
+	pocket func(*Cube)
+	cube   func(*bytecube.Cube)
+}{
+	"R":  {(*Cube).RotateR, (*bytecube.Cube).RotateR},
+	"R'": {(*Cube).RotateRCounter, (*bytecube.Cube).RotateRCounter},
+	"L":  {(*Cube).RotateL, (*bytecube.Cube).RotateL},
+	"L'": {(*Cube).RotateLCounter, (*bytecube.Cube).RotateLCounter},
+	"U":  {(*Cube).RotateU, (*bytecube.Cube).RotateU},
+	"U'": {(*Cube).RotateUCounter, (*bytecube.Cube).RotateUCounter},
+	"D":  {(*Cube).RotateD, (*bytecube.Cube).RotateD},
+	"D'": {(*Cube).RotateDCounter, (*bytecube.Cube).RotateDCounter},
+	"F":  {(*Cube).RotateF, (*bytecube.Cube).RotateF},
+	"F'": {(*Cube).RotateFCounter, (*bytecube.Cube).RotateFCounter},
+	"B":  {(*Cube).RotateB, (*bytecube.Cube).RotateB},
+	"B'": {(*Cube).RotateBCounter, (*bytecube.Cube).RotateBCounter},
+}
+
+//cornersOf returns the corner stickers of a 3x3 cube, which are a pocket cube
+func cornersOf(b *bytecube.Cube) string {
+	s := b.String()
+	result := ""
+	for side := 0; side < 6; side++ {
+		for _, spot := range []int{0, 2, 6, 8} {
+			result += string(s[9*side+spot])
+		}
+	}
+	return result
+}
+
+//scrambled applies moves in official notation to a solved pocket cube
+func scrambled(moves string) *Cube {
+	c, _ := NewCube(SOLVED)
+	rubikscuberunner.NewOfficialRunner(c).Run(moves)
+	return c
+}
+
+func TestRotations(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	names := make([]string, 0, len(rotations))
+	for name := range rotations {
+		names = append(names, name)
+	}
+	c, _ := NewCube(SOLVED)
+	b, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+	for i := 0; i < 200; i++ {
+		name := names[r.Intn(len(names))]
+		rotations[name].pocket(c)
+		rotations[name].cube(b)
+		if c.String() != cornersOf(b) {
+			t.Fatal("Failed rotation ", name, " got: ", c.String(), " expected: ", cornersOf(b))
+		}
+		if _, err := c.Validate(); err != nil {
+			t.Fatal("Failed Validate after ", name, " got: ", err)
+		}
+	}
+	c, _ = NewCube(SOLVED)
+	for i := 0; i < 4; i++ {
+		c.RotateR()
+		if c.Solved() != (i == 3) {
+			t.Error("Failed Solved after ", i+1, " R")
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	twisted := []byte(SOLVED)
+	//turn the URF corner's stickers U, R, F one place
+	twisted[4*4+3], twisted[3*4+0], twisted[0*4+1] = '0', '4', '3'
+	tests := []struct {
+		state string
+		err   error
+	}{
+		{SOLVED, nil},
+		{scrambled("R U F' L D' B").String(), nil},
+		{SOLVED[:23], ErrIncorrectNumber},
+		{SOLVED[:23] + "6", bytecube.ErrInvalidColor},
+		{SOLVED[:23] + "4", ErrIncorrectColorNumbers},
+		{string(twisted), bytecube.ErrTwistedCorner},
+		//swapping two stickers of a corner mirrors it
+		{SOLVED[:1] + "1" + SOLVED[2:4] + "0" + SOLVED[5:], bytecube.ErrIncorrectCorners},
+		//the U and D stickers of the left back corners swapped
+		{SOLVED[:16] + "5" + SOLVED[17:22] + "4" + SOLVED[23:], bytecube.ErrIncorrectCorners},
+		//without centers the front and back swapped are a solved cube with its colors mirrored
+		{"222211110000333344445555", nil},
+	}
+	for _, x := range tests {
+		c, err := NewValidCube(x.state)
+		if err != x.err {
+			t.Error("Failed NewValidCube for ", x.state, " got: ", err, " expected: ", x.err)
+		}
+		if err == nil && c.String() != x.state {
+			t.Error("Failed NewValidCube got: ", c.String())
+		}
+	}
+	c, _ := NewCube("222211110000333344445555")
+	if colors, err := c.FaceColors(); err != nil || colors != [6]int{2, 1, 0, 3, 4, 5} {
+		t.Error("Failed FaceColors got: ", colors, err)
+	}
+	if _, err := scrambled("R U").FaceColors(); err != nil {
+		t.Error("Failed FaceColors got: ", err)
+	}
+}
+
+func TestTable(t *testing.T) {
+	table := GenerateTable()
+	//the number of positions at each distance in half turns
+	expected := []int{1, 9, 54, 321, 1847, 9992, 50136, 227536, 870072, 1887748, 623800, 2644}
+	counts := make([]int, len(expected))
+	for _, d := range table.data {
+		if int(d) >= len(counts) {
+			t.Fatal("Failed GenerateTable distance ", d)
+		}
+		counts[d]++
+	}
+	for i := range expected {
+		if counts[i] != expected[i] {
+			t.Error("Failed GenerateTable distance ", i, " got: ", counts[i], " expected: ", expected[i])
+		}
+	}
+	var b bytes.Buffer
+	if err := table.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ReadTable(&b)
+	if err != nil || !bytes.Equal(loaded.data, table.data) {
+		t.Error("Failed ReadTable got: ", err)
+	}
+	if _, err := ReadTable(strings.NewReader("RCPD")); err != ErrNotTable {
+		t.Error("Failed ReadTable of another file got: ", err)
+	}
+	dir := t.TempDir()
+	saved, err := LoadDefaultTable(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err = LoadTable(filepath.Join(dir, TABLE_FILE))
+	if err != nil || !bytes.Equal(loaded.data, saved.data) {
+		t.Error("Failed LoadDefaultTable saving the table got: ", err)
+	}
+
+	r := rand.New(rand.NewSource(2))
+	names := []string{"R", "R'", "L", "L'", "U", "U'", "D", "D'", "F", "F'", "B", "B'"}
+	for i := 0; i < 50; i++ {
+		moves := make([]string, 20)
+		for j := range moves {
+			moves[j] = names[r.Intn(len(names))]
+		}
+		//every face is turned so the DBL corner the solver keeps in place is moved as well
+		c := scrambled(strings.Join(moves, " "))
+		s, err := NewSolver(c, table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		solution, err := s.Solve()
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, _ := table.Distance(c)
+		if len(strings.Fields(solution)) != d {
+			t.Error("Failed Solve length ", solution, " expected ", d, " moves")
+		}
+		if err := rubikscuberunner.NewOfficialRunner(c).Run(solution); err != nil {
+			t.Fatal(err)
+		}
+		if !c.Solved() {
+			t.Error("Failed Solve of ", strings.Join(moves, " "), " got: ", solution)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package pocketcube
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"github.com/davidafox/rubikscubesolver/solver"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+//The solver keeps the DBL corner in place and turns only R, U and F, so a position is the arrangement and twists of
+//the other seven corners. There are 7! * 3^6 = 3,674,160 of them, few enough to store the distance of every one
+//from solved. The table is built once with a breadth first search and a solution follows it down to 0.
+
+var ErrNotTable = errors.New("The file is not a pocket cube distance table")
+var ErrTableVersion = errors.New("The distance table was written by an unsupported version")
+
+const TABLE_VERSION = 1
+
+//N_PERM and N_TWIST are the number of arrangements and of twists of the seven corners that move
+const N_PERM = 5040
+const N_TWIST = 729
+
+//TABLE_SIZE is the number of positions
+const TABLE_SIZE = N_PERM * N_TWIST
+
+//TABLE_FILE is the name of the table in a table directory
+const TABLE_FILE = "pocket.rcpt"
+
+var tableMagic = [4]byte{'R', 'C', 'P', 'T'}
+
+//unvisited marks positions the breadth first search has not reached yet
+const unvisited = 0xFF
+
+//N_MOVE is the number of moves the solver uses, R, U and F each turned once, twice or three times
+const N_MOVE = 9
+
+//moveSides holds the side turned by each face of the moves
+var moveSides = [3]int{3, 4, 0}
+
+var moveNames = [N_MOVE]string{"R", "R2", "R'", "U", "U2", "U'", "F", "F2", "F'"}
+
+//permMove and twistMove hold the coordinate reached by each move from each coordinate
+var permMove [N_PERM][N_MOVE]uint16
+var twistMove [N_TWIST][N_MOVE]uint16
+
+func init() {
+	for i := 0; i < N_PERM; i++ {
+		x := new(corners)
+		decodePerm(x, i)
+		for f, side := range moveSides {
+			for t := 0; t < 3; t++ {
+				x = x.move(side)
+				permMove[i][3*f+t] = uint16(permIndex(x))
+			}
+			x = x.move(side)
+		}
+	}
+	for i := 0; i < N_TWIST; i++ {
+		x := new(corners)
+		decodePerm(x, 0)
+		decodeTwist(x, i)
+		for f, side := range moveSides {
+			for t := 0; t < 3; t++ {
+				x = x.move(side)
+				twistMove[i][3*f+t] = uint16(twistIndex(x))
+			}
+			x = x.move(side)
+		}
+	}
+}
+
+//permIndex numbers the arrangement of the corners in the positions other than DBL, which always holds its own corner
+func permIndex(x *corners) int {
+	var pieces [7]int
+	n := 0
+	for p := range x.perm {
+		if p != DBL {
+			pieces[n] = int(x.perm[p])
+			if pieces[n] == DRB {
+				pieces[n] = DBL
+			}
+			n++
+		}
+	}
+	idx := 0
+	for i := 0; i < 7; i++ {
+		smaller := 0
+		for j := i + 1; j < 7; j++ {
+			if pieces[j] < pieces[i] {
+				smaller++
+			}
+		}
+		idx = idx*(7-i) + smaller
+	}
+	return idx
+}
+
+//decodePerm sets the arrangement numbered idx
+func decodePerm(x *corners, idx int) {
+	var lehmer [7]int
+	for i := 6; i >= 0; i-- {
+		lehmer[i] = idx % (7 - i)
+		idx /= 7 - i
+	}
+	remaining := []int{0, 1, 2, 3, 4, 5, 6}
+	n := 0
+	for p := range x.perm {
+		if p == DBL {
+			x.perm[p] = DBL
+			continue
+		}
+		piece := remaining[lehmer[n]]
+		remaining = append(remaining[:lehmer[n]], remaining[lehmer[n]+1:]...)
+		if piece == DBL {
+			piece = DRB
+		}
+		x.perm[p] = int8(piece)
+		n++
+	}
+}
+
+//twistIndex numbers the twists of the positions before DBL, the others are fixed by the total being a multiple of 3
+func twistIndex(x *corners) int {
+	idx := 0
+	for p := 0; p < DBL; p++ {
+		idx = idx*3 + int(x.twist[p])
+	}
+	return idx
+}
+
+//decodeTwist sets the twists numbered idx
+func decodeTwist(x *corners, idx int) {
+	sum := 0
+	for p := DBL - 1; p >= 0; p-- {
+		x.twist[p] = int8(idx % 3)
+		sum += idx % 3
+		idx /= 3
+	}
+	x.twist[DBL] = 0
+	x.twist[DRB] = int8((3 - sum%3) % 3)
+}
+
+func index(x *corners) int {
+	return permIndex(x)*N_TWIST + twistIndex(x)
+}
+
+//Table holds the number of moves needed to solve every position
+type Table struct {
+	data []byte
+}
+
+//GenerateTable fills in a table with a breadth first search from the solved cube, it takes under a second
+func GenerateTable() *Table {
+	t := &Table{make([]byte, TABLE_SIZE)}
+	for i := range t.data {
+		t.data[i] = unvisited
+	}
+	solved := new(corners)
+	decodePerm(solved, 0)
+	t.data[index(solved)] = 0
+	for depth, found := byte(0), true; found; depth++ {
+		found = false
+		for i, d := range t.data {
+			if d != depth {
+				continue
+			}
+			perm, twist := i/N_TWIST, i%N_TWIST
+			for m := 0; m < N_MOVE; m++ {
+				next := int(permMove[perm][m])*N_TWIST + int(twistMove[twist][m])
+				if t.data[next] == unvisited {
+					t.data[next] = depth + 1
+					found = true
+				}
+			}
+		}
+	}
+	return t
+}
+
+//Distance returns the number of moves needed to solve c, which must be valid
+func (t *Table) Distance(c *Cube) (int, error) {
+	x, err := c.corners()
+	if err != nil {
+		return 0, err
+	}
+	return int(t.data[index(x)]), nil
+}
+
+//Write saves the table in a versioned binary format
+func (t *Table) Write(w io.Writer) error {
+	header := []byte{tableMagic[0], tableMagic[1], tableMagic[2], tableMagic[3]}
+	header = binary.LittleEndian.AppendUint16(header, TABLE_VERSION)
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(t.data)
+	return err
+}
+
+//ReadTable loads a table saved by Write
+func ReadTable(r io.Reader) (*Table, error) {
+	header := make([]byte, 6)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, ErrNotTable
+	}
+	if header[0] != tableMagic[0] || header[1] != tableMagic[1] || header[2] != tableMagic[2] || header[3] != tableMagic[3] {
+		return nil, ErrNotTable
+	}
+	if binary.LittleEndian.Uint16(header[4:6]) != TABLE_VERSION {
+		return nil, ErrTableVersion
+	}
+	t := &Table{make([]byte, TABLE_SIZE)}
+	if _, err := io.ReadFull(r, t.data); err != nil {
+		return nil, ErrNotTable
+	}
+	return t, nil
+}
+
+//Save writes the table to the file at path
+func (t *Table) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if err := t.Write(w); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+//LoadTable reads a table saved with Save
+func LoadTable(path string) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadTable(bufio.NewReader(f))
+}
+
+//loadedTables keeps the table of each directory so it is only loaded or generated once
+var loadedTables = struct {
+	sync.Mutex
+	dirs map[string]*Table
+}{dirs: make(map[string]*Table)}
+
+//LoadDefaultTable loads the table from dir, generating and saving it when it is missing.
+//An empty dir generates the table without saving it.
+func LoadDefaultTable(dir string) (*Table, error) {
+	loadedTables.Lock()
+	defer loadedTables.Unlock()
+	if t, ok := loadedTables.dirs[dir]; ok {
+		return t, nil
+	}
+	var t *Table
+	if dir == "" {
+		t = GenerateTable()
+	} else {
+		path := filepath.Join(dir, TABLE_FILE)
+		var err error
+		t, err = LoadTable(path)
+		if os.IsNotExist(err) {
+			t = GenerateTable()
+			err = t.Save(path)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	loadedTables.dirs[dir] = t
+	return t, nil
+}
+
+type Solver struct {
+	position *corners
+	table    *Table
+	observer solver.Observer
+}
+
+//NewSolver returns a solver for c using a generated table
+func NewSolver(c *Cube, t *Table) (*Solver, error) {
+	x, err := c.corners()
+	if err != nil {
+		return nil, err
+	}
+	s := new(Solver)
+	s.position = x
+	s.table = t
+	return s, nil
+}
+
+//SetObserver sets the observer that receives the progress of the solve, by default nothing is reported
+func (s *Solver) SetObserver(o solver.Observer) {
+	s.observer = o
+}
+
+//Solve returns a shortest solution in official notation
+func (s *Solver) Solve() (string, error) {
+	return s.SolveContext(context.Background())
+}
+
+//SolveContext returns a shortest solution, at most 11 moves of the table so ctx is only checked before starting
+func (s *Solver) SolveContext(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", &solver.CancelError{Err: err}
+	}
+	perm, twist := permIndex(s.position), twistIndex(s.position)
+	var steps []string
+	for d := s.table.data[perm*N_TWIST+twist]; d > 0; d-- {
+		for m := 0; m < N_MOVE; m++ {
+			p, t := int(permMove[perm][m]), int(twistMove[twist][m])
+			if s.table.data[p*N_TWIST+t] == d-1 {
+				steps = append(steps, moveNames[m])
+				perm, twist = p, t
+				break
+			}
+		}
+	}
+	solution := strings.Join(steps, " ")
+	s.observer.Notify(solver.Event{Kind: solver.SolutionFound, Depth: len(steps), Solution: solution})
+	return solution, nil
+}
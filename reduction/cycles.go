@@ -0,0 +1,157 @@
+package reduction
+
+import (
+	"github.com/davidafox/rubikscubesolver/notation"
+	"sort"
+	"sync"
+)
+
+//Centers and wings are solved with 3-cycles, sequences that move three pieces of one kind round and leave
+//everything else in place. The shortest are commutators X Y X' Y' of an inner quarter turn X and up to three
+//moves Y, found by trying them all. Conjugating a 3-cycle by a move, m C m', gives a 3-cycle of other positions,
+//so a breadth first search over conjugations from the commutators reaches a sequence for every 3-cycle.
+
+//cycle is a sequence moving the piece in slots[0] to slots[1], the one in slots[1] to slots[2] and the one in slots[2]
+//to slots[0]
+type cycle struct {
+	slots [3]int8
+	moves notation.Sequence
+}
+
+//wingCycles and centerCycles hold the shortest sequence found for every 3-cycle of wings and of centers
+var wingCycles, centerCycles []cycle
+
+var cyclesOnce sync.Once
+
+//moveSlots holds the slots of each of moves
+var moveSlots []slots
+
+//key returns the slots of a 3-cycle starting with the smallest so each 3-cycle has one key
+func key(a, b, c int8) [3]int8 {
+	for a > b || a > c {
+		a, b, c = b, c, a
+	}
+	return [3]int8{a, b, c}
+}
+
+//initCycles finds the commutators and conjugates them, it takes about a second
+func initCycles() {
+	moveSlots = make([]slots, len(movePerms))
+	for i := range movePerms {
+		moveSlots[i] = slotsOf(&movePerms[i])
+	}
+	wings := make(map[[3]int8]notation.Sequence)
+	centers := make(map[[3]int8]notation.Sequence)
+	var search func(y notation.Sequence, py perm)
+	search = func(y notation.Sequence, py perm) {
+		if len(y) > 0 {
+			commutators(y, &py, wings, centers)
+		}
+		if len(y) == 3 {
+			return
+		}
+		for i, m := range moves {
+			if n := len(y); n > 0 && y[n-1].Face == m.Face && y[n-1].Inner == m.Inner {
+				continue
+			}
+			search(append(y[:len(y):len(y)], m), py.then(&movePerms[i]))
+		}
+	}
+	search(notation.Sequence{}, identity)
+	wingCycles = conjugates(wings, func(s *slots) *[N_WING]int8 { return &s.wing })
+	centerCycles = conjugates(centers, func(s *slots) *[N_WING]int8 { return &s.center })
+}
+
+//commutators adds the pure 3-cycles X Y X' Y' with X an inner quarter turn
+func commutators(y notation.Sequence, py *perm, wings, centers map[[3]int8]notation.Sequence) {
+	yi := py.inverse()
+	for i, x := range moves {
+		if !x.Inner || x.Turns == 2 {
+			continue
+		}
+		xi := movePerms[i].inverse()
+		p := movePerms[i].then(py)
+		p = p.then(&xi)
+		p = p.then(&yi)
+		moved, wing, center := 0, 0, 0
+		for j, from := range p {
+			if int(from) != j {
+				moved++
+				if wingAt[j] >= 0 {
+					wing++
+				}
+				if centerAt[j] >= 0 {
+					center++
+				}
+			}
+		}
+		var found map[[3]int8]notation.Sequence
+		var to []int8
+		s := slotsOf(&p)
+		switch {
+		case moved == 6 && wing == 6:
+			found, to = wings, s.wing[:]
+		case moved == 3 && center == 3:
+			found, to = centers, s.center[:]
+		default:
+			continue
+		}
+		a := int8(0)
+		for to[a] == a {
+			a++
+		}
+		k := key(a, to[a], to[to[a]])
+		if old, ok := found[k]; !ok || len(old) > 2+2*len(y) {
+			seq := notation.Sequence{x}
+			seq = append(seq, y...)
+			seq = append(seq, x.Inverse())
+			found[k] = append(seq, y.Inverse()...)
+		}
+	}
+}
+
+//conjugates adds a sequence for every 3-cycle reachable by conjugating those found, to gives the slots of a move
+func conjugates(found map[[3]int8]notation.Sequence, to func(*slots) *[N_WING]int8) []cycle {
+	level := make([][3]int8, 0, len(found))
+	for k := range found {
+		level = append(level, k)
+	}
+	for len(level) > 0 {
+		sort.Slice(level, func(i, j int) bool { return less(level[i], level[j]) })
+		var next [][3]int8
+		for _, k := range level {
+			for i, m := range moves {
+				//the piece m takes to slot k[j] comes from back[k[j]]
+				dest := to(&moveSlots[i])
+				var back [N_WING]int8
+				for from, d := range dest {
+					back[d] = int8(from)
+				}
+				c := key(back[k[0]], back[k[1]], back[k[2]])
+				if _, ok := found[c]; ok {
+					continue
+				}
+				seq := notation.Sequence{m}
+				seq = append(seq, found[k]...)
+				found[c] = append(seq, m.Inverse())
+				next = append(next, c)
+			}
+		}
+		level = next
+	}
+	result := make([]cycle, 0, len(found))
+	for k, seq := range found {
+		result = append(result, cycle{k, seq})
+	}
+	sort.Slice(result, func(i, j int) bool { return less(result[i].slots, result[j].slots) })
+	return result
+}
+
+func less(a, b [3]int8) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package reduction
+
+import (
+	"errors"
+	"github.com/davidafox/rubikscubesolver/notation"
+	"github.com/davidafox/rubikscubesolver/rubikscube"
+	"strings"
+)
+
+var ErrMiddleLayer = errors.New("A 4x4 has no middle layer to turn")
+
+//SIZE is the size of the cubes solved
+const SIZE = 4
+
+//N_STICKER is the number of stickers of a 4x4
+const N_STICKER = 6 * SIZE * SIZE
+
+//SOLVED is the state of a solved 4x4, 16 stickers of each color side by side in the order of rubikscube.Cube
+const SOLVED = "0000000000000000111111111111111122222222222222223333333333333333" +
+	"44444444444444445555555555555555"
+
+const faces = "RLUDFB"
+
+//rotationFaces holds the face each whole cube rotation turns like
+var rotationFaces = map[byte]byte{'x': 'R', 'y': 'U', 'z': 'F'}
+
+//Parse reads moves in SiGN notation such as R U' 2R2 Rw or x, the wide moves may also be written r.
+//A 4x4 has no middle layer so M E S give ErrMiddleLayer.
+func Parse(s string) (notation.Sequence, error) {
+	seq, err := notation.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range seq {
+		if strings.IndexByte(faces, m.Face) < 0 && rotationFaces[m.Face] == 0 {
+			return nil, ErrMiddleLayer
+		}
+	}
+	return seq, nil
+}
+
+//Apply turns c, which must be a 4x4, by the face turns and rotations of seq
+func Apply(c *rubikscube.Cube, seq notation.Sequence) {
+	for _, m := range seq {
+		first, last := 0, 0
+		switch {
+		case rotationFaces[m.Face] != 0:
+			m.Face, last = rotationFaces[m.Face], SIZE-1
+		case m.Inner:
+			first, last = 1, 1
+		case m.Wide:
+			last = 1
+		}
+		c.RotateLayers(m.Face, first, last, m.Turns)
+	}
+}
+
+//perm holds the sticker moved to each place by a move, so after the move sticker i is the one that was at perm[i]
+type perm [N_STICKER]uint8
+
+var identity perm
+
+//moves holds every outer and inner turn, the moves the solver searches with
+var moves []notation.Move
+
+//movePerms holds the perm of each of moves
+var movePerms []perm
+
+func init() {
+	for i := range identity {
+		identity[i] = uint8(i)
+	}
+	for _, inner := range []bool{false, true} {
+		for i := range faces {
+			for turns := 1; turns <= 3; turns++ {
+				m := notation.Move{Face: faces[i], Turns: turns, Inner: inner}
+				moves = append(moves, m)
+				movePerms = append(movePerms, moveSequencePerm(notation.Sequence{m}))
+			}
+		}
+	}
+}
+
+//moveSequencePerm finds the perm of s by turning a cube whose stickers are numbered
+func moveSequencePerm(s notation.Sequence) perm {
+	labels := make([]byte, N_STICKER)
+	for i := range labels {
+		labels[i] = byte(i)
+	}
+	c := rubikscube.NewCube(string(labels), SIZE)
+	Apply(c, s)
+	var p perm
+	copy(p[:], c.String())
+	return p
+}
+
+//then returns the perm of p followed by q
+func (p *perm) then(q *perm) perm {
+	var result perm
+	for i, from := range q {
+		result[i] = p[from]
+	}
+	return result
+}
+
+func (p *perm) inverse() perm {
+	var result perm
+	for i, from := range p {
+		result[from] = uint8(i)
+	}
+	return result
+}
+
+//apply returns the stickers after the perm
+func (p *perm) apply(stickers *[N_STICKER]byte) {
+	var next [N_STICKER]byte
+	for i, from := range p {
+		next[i] = stickers[from]
+	}
+	*stickers = next
+}
@@ -0,0 +1,131 @@
+package reduction
+
+//A 4x4 has 8 corners, 24 wings, two on each edge, and 24 centers, four on each side.
+//Pieces are found from the position of their stickers with the cube's center at 0, the stickers' centers
+//at odd numbers from -3 to 3 and the sides at 4. The front is toward positive z, the right toward positive x
+//and the top toward positive y, as in bytecube's symmetries.
+
+//stickerPosition returns the position of sticker i, the sides and their stickers are in the order of rubikscube.Cube
+func stickerPosition(i int) [3]int {
+	side, r, c := i/(SIZE*SIZE), i%(SIZE*SIZE)/SIZE, i%SIZE
+	switch side {
+	case 0:
+		return [3]int{-3 + 2*c, 3 - 2*r, 4}
+	case 1:
+		return [3]int{-4, 3 - 2*r, -3 + 2*c}
+	case 2:
+		return [3]int{3 - 2*c, 3 - 2*r, -4}
+	case 3:
+		return [3]int{4, 3 - 2*r, 3 - 2*c}
+	case 4:
+		return [3]int{-3 + 2*c, 4, -3 + 2*r}
+	}
+	return [3]int{-3 + 2*c, -4, 3 - 2*r}
+}
+
+//piecePosition returns the position of the piece a sticker is on
+func piecePosition(i int) [3]int {
+	p := stickerPosition(i)
+	for axis := range p {
+		if p[axis] == 4 {
+			p[axis] = 3
+		}
+		if p[axis] == -4 {
+			p[axis] = -3
+		}
+	}
+	return p
+}
+
+//normal returns the direction out of the side sticker i is on
+func normal(i int) [3]int {
+	p := stickerPosition(i)
+	var n [3]int
+	for axis := range p {
+		n[axis] = p[axis] / 4
+	}
+	return n
+}
+
+func determinant(a, b, c [3]int) int {
+	return a[0]*(b[1]*c[2]-b[2]*c[1]) - a[1]*(b[0]*c[2]-b[2]*c[0]) + a[2]*(b[0]*c[1]-b[1]*c[0])
+}
+
+const N_WING = 24
+const N_CENTER = 24
+
+//wingStickers holds the two stickers of each wing position in the order that turns the same way around the wing,
+//so a wing's colors read in that order tell it apart from the other wing of its edge
+var wingStickers [N_WING][2]int
+
+//centerStickers holds the sticker of each center position
+var centerStickers [N_CENTER]int
+
+//cornerStickers holds the three stickers of each corner position
+var cornerStickers [8][3]int
+
+//wingAt and centerAt hold the wing or center position of each sticker, -1 for other pieces
+var wingAt, centerAt [N_STICKER]int
+
+func init() {
+	byPiece := make(map[[3]int][]int)
+	var order [][3]int
+	for i := 0; i < N_STICKER; i++ {
+		p := piecePosition(i)
+		if _, ok := byPiece[p]; !ok {
+			order = append(order, p)
+		}
+		byPiece[p] = append(byPiece[p], i)
+		wingAt[i], centerAt[i] = -1, -1
+	}
+	wings, centers, corners := 0, 0, 0
+	for _, p := range order {
+		stickers := byPiece[p]
+		switch len(stickers) {
+		case 1:
+			centerStickers[centers] = stickers[0]
+			centerAt[stickers[0]] = centers
+			centers++
+		case 2:
+			//the wing's offset along its edge, the axis that isn't at a side
+			var along [3]int
+			for axis := range p {
+				if p[axis] == 1 || p[axis] == -1 {
+					along[axis] = p[axis]
+				}
+			}
+			a, b := stickers[0], stickers[1]
+			if determinant(normal(a), normal(b), along) < 0 {
+				a, b = b, a
+			}
+			wingStickers[wings] = [2]int{a, b}
+			wingAt[a], wingAt[b] = wings, wings
+			wings++
+		case 3:
+			copy(cornerStickers[corners][:], stickers)
+			corners++
+		}
+	}
+}
+
+//slots holds where a move takes the piece in each wing and center position
+type slots struct {
+	wing   [N_WING]int8
+	center [N_CENTER]int8
+}
+
+//slotsOf returns where p takes each wing and center
+func slotsOf(p *perm) slots {
+	var dest [N_STICKER]int
+	for i, from := range p {
+		dest[from] = i
+	}
+	var s slots
+	for w, stickers := range wingStickers {
+		s.wing[w] = int8(wingAt[dest[stickers[0]]])
+	}
+	for c, sticker := range centerStickers {
+		s.center[c] = int8(centerAt[dest[sticker]])
+	}
+	return s
+}
@@ -0,0 +1,251 @@
+package reduction
+
+import (
+	"context"
+	"errors"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/notation"
+	"github.com/davidafox/rubikscubesolver/rubikscube"
+	"github.com/davidafox/rubikscubesolver/solver"
+	_ "github.com/davidafox/rubikscubesolver/twophase"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+//scrambled applies moves in SiGN notation to a solved 4x4
+func scrambled(moves string) *rubikscube.Cube {
+	c := rubikscube.NewCube(SOLVED, SIZE)
+	Apply(c, notation.MustParse(moves))
+	return c
+}
+
+//solved is true when every side is one color
+func solved(state string) bool {
+	for side := 0; side < 6; side++ {
+		for spot := 1; spot < SIZE*SIZE; spot++ {
+			if state[side*SIZE*SIZE+spot] != state[side*SIZE*SIZE] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		moves    string
+		expected string
+		err      error
+	}{
+		{"R U' F2", "R U' F2", nil},
+		{"2R 2L' 2U2", "2R 2L' 2U2", nil},
+		{"Rw r' Dw2 b", "Rw Rw' Dw2 Bw", nil},
+		{"  R2'  ", "R2", nil},
+		{"", "", nil},
+		{"R3 x y2", "R' x y2", nil},
+		{"2Rw", "", notation.ErrUnknownMove},
+		{"2r", "", notation.ErrUnknownMove},
+		{"X", "", notation.ErrUnknownMove},
+		{"2", "", notation.ErrUnknownMove},
+		{"R M'", "", ErrMiddleLayer},
+	}
+	for _, x := range tests {
+		seq, err := Parse(x.moves)
+		if !errors.Is(err, x.err) {
+			t.Error("Failed Parse of ", x.moves, " got: ", err, " expected: ", x.err)
+		}
+		if err == nil && seq.String() != x.expected {
+			t.Error("Failed Parse of ", x.moves, " got: ", seq.String(), " expected: ", x.expected)
+		}
+	}
+	seq, _ := Parse("Rw U2 2L' Fw Dw")
+	c := scrambled(seq.String())
+	Apply(c, seq.Inverse())
+	if c.String() != SOLVED {
+		t.Error("Failed Inverse got: ", c.String())
+	}
+	//a wide turn is the face and the slice next to it
+	if scrambled("Rw").String() != scrambled("R 2R").String() || scrambled("Uw'").String() != scrambled("2U' U'").String() {
+		t.Error("Failed Apply of a wide turn")
+	}
+	//a rotation turns every layer
+	if scrambled("x").String() != scrambled("Rw L' 2L'").String() || !solved(scrambled("y z2").String()) {
+		t.Error("Failed Apply of a rotation")
+	}
+}
+
+func TestCycles(t *testing.T) {
+	cyclesOnce.Do(initCycles)
+	index := make(map[notation.Move]int)
+	for i, m := range moves {
+		index[m] = i
+	}
+	for _, kind := range []struct {
+		name     string
+		cycles   []cycle
+		slots    func(*slots) *[N_WING]int8
+		stickers int
+	}{
+		{"wing", wingCycles, func(s *slots) *[N_WING]int8 { return &s.wing }, 6},
+		{"center", centerCycles, func(s *slots) *[N_WING]int8 { return &s.center }, 3},
+	} {
+		//every 3-cycle of 24 pieces
+		if len(kind.cycles) != 24*23*22/3 {
+			t.Error("Failed ", kind.name, " cycles got: ", len(kind.cycles))
+		}
+		for _, c := range kind.cycles {
+			p := identity
+			for _, m := range c.moves {
+				p = p.then(&movePerms[index[m]])
+			}
+			moved := 0
+			for i, from := range p {
+				if int(from) != i {
+					moved++
+				}
+			}
+			s := slotsOf(&p)
+			to := kind.slots(&s)
+			a, b, d := c.slots[0], c.slots[1], c.slots[2]
+			if to[a] != b || to[b] != d || to[d] != a || moved != kind.stickers {
+				t.Fatal("Failed ", kind.name, " cycle ", c.slots, " moves: ", c.moves)
+			}
+		}
+	}
+}
+
+func TestParity(t *testing.T) {
+	tests := []struct {
+		alg string
+		//the stickers of the edges the algorithm moves
+		changed []int
+	}{
+		//the UF wings
+		{OLL_PARITY, []int{1, 2, 77, 78}},
+		//the UF and UB wings, whose U stickers are the same color
+		{PLL_PARITY, []int{1, 2, 33, 34}},
+	}
+	for _, x := range tests {
+		got := scrambled(x.alg).String()
+		var changed []int
+		for i := range got {
+			if got[i] != SOLVED[i] {
+				changed = append(changed, i)
+			}
+		}
+		if len(changed) != len(x.changed) {
+			t.Error("Failed ", x.alg, " changed: ", changed, " expected: ", x.changed)
+			continue
+		}
+		for i := range changed {
+			if changed[i] != x.changed[i] {
+				t.Error("Failed ", x.alg, " changed: ", changed, " expected: ", x.changed)
+				break
+			}
+		}
+	}
+}
+
+func TestNewSolver(t *testing.T) {
+	//the UFR corner's stickers turned
+	twisted := []byte(SOLVED)
+	twisted[4*16+15], twisted[3*16+0], twisted[0*16+3] = '0', '4', '3'
+	tests := []struct {
+		state string
+		size  int
+		err   error
+	}{
+		{SOLVED, SIZE, nil},
+		{scrambled("Rw U 2F' L2 Dw").String(), SIZE, nil},
+		{"000000000111111111222222222333333333444444444555555555", 3, ErrIncorrectNumber},
+		{SOLVED[:95] + "6", SIZE, bytecube.ErrInvalidColor},
+		{SOLVED[:95] + "4", SIZE, ErrIncorrectColorNumbers},
+		{string(twisted), SIZE, bytecube.ErrTwistedCorner},
+		//a flipped wing has the colors of the other wing of its edge
+		{SOLVED[:1] + "4" + SOLVED[2:77] + "0" + SOLVED[78:], SIZE, bytecube.ErrIncorrectSides},
+	}
+	for _, x := range tests {
+		_, err := NewSolver(rubikscube.NewCube(x.state, x.size), "twophase", solver.Options{})
+		if err != x.err {
+			t.Error("Failed NewSolver for ", x.state, " got: ", err, " expected: ", x.err)
+		}
+	}
+}
+
+func TestSolve(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	names := []string{"R", "L", "U", "D", "F", "B", "2R", "2L", "2U", "2D", "2F", "2B", "Rw", "Lw", "Uw", "Dw", "Fw", "Bw"}
+	scrambles := []string{
+		"",
+		"2R",
+		OLL_PARITY,
+		PLL_PARITY,
+		OLL_PARITY + " " + PLL_PARITY + " R U F",
+	}
+	for i := 0; i < 8; i++ {
+		moves := make([]string, 30)
+		for j := range moves {
+			moves[j] = names[r.Intn(len(names))] + []string{"", "'", "2"}[r.Intn(3)]
+		}
+		scrambles = append(scrambles, strings.Join(moves, " "))
+	}
+	for _, scramble := range scrambles {
+		c := scrambled(scramble)
+		s, err := NewSolver(c, "twophase", solver.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var found string
+		s.SetObserver(func(e solver.Event) {
+			if e.Kind == solver.SolutionFound {
+				found = e.Solution
+			}
+		})
+		solution, err := s.Solve()
+		if err != nil {
+			t.Fatal("Failed Solve of ", scramble, " got: ", err)
+		}
+		if found != solution {
+			t.Error("Failed Solve observer got: ", found, " expected: ", solution)
+		}
+		seq, err := Parse(solution)
+		if err != nil {
+			t.Fatal(err)
+		}
+		Apply(c, seq)
+		if !solved(c.String()) {
+			t.Error("Failed Solve of ", scramble, " got: ", solution)
+		}
+	}
+}
+
+//cancelAfter is a context that is canceled once its Err has been checked n times
+type cancelAfter struct {
+	context.Context
+	n int
+}
+
+func (c *cancelAfter) Err() error {
+	if c.n--; c.n < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestSolveContext(t *testing.T) {
+	s, err := NewSolver(scrambled("Rw U 2F' L2 Dw 2R"), "twophase", solver.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := 0
+	s.SetObserver(func(e solver.Event) {
+		events++
+	})
+	//the first check is before the centers are reduced, the second is while they are so the 3x3 solver isn't started
+	_, err = s.SolveContext(&cancelAfter{context.Background(), 1})
+	var cancelErr *solver.CancelError
+	if !errors.As(err, &cancelErr) || !errors.Is(err, context.Canceled) || events != 0 {
+		t.Error("Failed SolveContext while reducing got: ", err, events, " expected: ", context.Canceled)
+	}
+}
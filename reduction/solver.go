@@ -0,0 +1,272 @@
+package reduction
+
+import (
+	"context"
+	"errors"
+	"github.com/davidafox/rubikscubesolver/algorithm"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/notation"
+	"github.com/davidafox/rubikscubesolver/pocketcube"
+	"github.com/davidafox/rubikscubesolver/rubikscube"
+	"github.com/davidafox/rubikscubesolver/solver"
+	"strings"
+)
+
+//The reduction method turns a 4x4 into a 3x3: the four centers of each side are solved, the two wings of each edge are
+//paired, and the cube is then solved with the outer face turns of a 3x3 solver. Two positions of a reduced 4x4 can't
+//happen on a 3x3, a single flipped edge, the OLL parity, and two swapped edges, the PLL parity. Each is fixed with an
+//algorithm before the 3x3 solver is used.
+//
+//A 4x4 has no fixed centers so the color of each side comes from the corners, the DBL corner is kept where it is.
+
+var ErrIncorrectNumber = errors.New("The cube must have 96 stickers")
+var ErrIncorrectColorNumbers = errors.New("There must be 16 of each color")
+var ErrNotReduced = errors.New("The centers and edges could not be reduced")
+
+//OLL_PARITY flips the wings of the UF edge leaving the rest of a reduced cube in place
+const OLL_PARITY = "2R2 B2 U2 2L U2 2R' U2 2R U2 F2 2R F2 2L' B2 2R2"
+
+//PLL_PARITY swaps the UF and UB edges leaving the rest of a reduced cube in place
+const PLL_PARITY = "2R2 U2 2R2 Uw2 2R2 Uw2 U2"
+
+//the 4x4 spots of the corners and of one wing of each edge of a side, in the order of the 3x3 spots 0, 2, 6, 8 and 1, 3, 5, 7
+var cornerSpots = [4]int{0, 3, 12, 15}
+var edgeSpots = [4]int{1, 4, 7, 13}
+
+//wingEdge holds the edge each wing position is on, the two wings of an edge are on the same two sides
+var wingEdge [N_WING]int
+
+func init() {
+	edges := make(map[[2]int]int)
+	for w, stickers := range wingStickers {
+		sides := [2]int{stickers[0] / (SIZE * SIZE), stickers[1] / (SIZE * SIZE)}
+		if sides[0] > sides[1] {
+			sides[0], sides[1] = sides[1], sides[0]
+		}
+		if _, ok := edges[sides]; !ok {
+			edges[sides] = len(edges)
+		}
+		wingEdge[w] = edges[sides]
+	}
+}
+
+type Solver struct {
+	stickers  [N_STICKER]byte
+	faceColor [6]byte
+	name      string
+	options   solver.Options
+	observer  solver.Observer
+}
+
+//NewSolver returns a solver for c, which must be a 4x4, handing the reduced cube to the 3x3 solver registered as name
+func NewSolver(c *rubikscube.Cube, name string, o solver.Options) (*Solver, error) {
+	state := c.String()
+	if len(state) != N_STICKER {
+		return nil, ErrIncorrectNumber
+	}
+	var count [6]int
+	for i := 0; i < N_STICKER; i++ {
+		if state[i] < '0' || state[i] > '5' {
+			return nil, bytecube.ErrInvalidColor
+		}
+		count[state[i]-'0']++
+	}
+	for _, n := range count {
+		if n != SIZE*SIZE {
+			return nil, ErrIncorrectColorNumbers
+		}
+	}
+	s := new(Solver)
+	copy(s.stickers[:], state)
+	s.name = name
+	s.options = o
+	//the corners alone are a pocket cube
+	corners := make([]byte, 0, 24)
+	for side := 0; side < 6; side++ {
+		for _, spot := range cornerSpots {
+			corners = append(corners, state[side*SIZE*SIZE+spot])
+		}
+	}
+	pocket, err := pocketcube.NewCube(string(corners))
+	if err != nil {
+		return nil, err
+	}
+	faceColor, err := pocket.FaceColors()
+	if err != nil {
+		return nil, err
+	}
+	for side, color := range faceColor {
+		s.faceColor[side] = byte('0' + color)
+	}
+	//every wing must be one of the wings of a solved cube, the centers are then the four left of each color
+	var found [N_WING]bool
+	for w := range wingStickers {
+		home := s.wingHome(&s.stickers, w)
+		if home < 0 || found[home] {
+			return nil, bytecube.ErrIncorrectSides
+		}
+		found[home] = true
+	}
+	return s, nil
+}
+
+//SetObserver sets the observer that receives the progress of the 3x3 solve and the solution, by default nothing is reported
+func (s *Solver) SetObserver(o solver.Observer) {
+	s.observer = o
+}
+
+//Solve returns a solution in SiGN notation
+func (s *Solver) Solve() (string, error) {
+	return s.SolveContext(context.Background())
+}
+
+//SolveContext stops reducing the cube or the 3x3 solver and returns a *solver.CancelError when ctx is canceled or its
+//deadline passes
+func (s *Solver) SolveContext(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", &solver.CancelError{Err: err}
+	}
+	cyclesOnce.Do(initCycles)
+	x := s.stickers
+	var solution notation.Sequence
+	//centers and wings are moved by 3-cycles, each chosen to solve as many as it can
+	for _, stage := range []struct {
+		cycles []cycle
+		done   func(*[N_STICKER]byte) int
+		apply  func(*[N_STICKER]byte, [3]int8)
+	}{
+		{centerCycles, s.centersSolved, cycleCenters},
+		{wingCycles, pairedEdges, cycleWings},
+	} {
+		for done := stage.done(&x); done < N_WING; {
+			if err := ctx.Err(); err != nil {
+				return "", &solver.CancelError{Err: err}
+			}
+			best, bestDone := -1, done
+			for i, c := range stage.cycles {
+				next := x
+				stage.apply(&next, c.slots)
+				n := stage.done(&next)
+				if n > bestDone || n == bestDone && best >= 0 && len(c.moves) < len(stage.cycles[best].moves) {
+					best, bestDone = i, n
+				}
+			}
+			if best < 0 {
+				return "", ErrNotReduced
+			}
+			stage.apply(&x, stage.cycles[best].slots)
+			solution = append(solution, stage.cycles[best].moves...)
+			done = bestDone
+		}
+	}
+	for _, parity := range []struct {
+		err error
+		alg string
+	}{{bytecube.ErrFlippedSide, OLL_PARITY}, {bytecube.ErrSwappedCubies, PLL_PARITY}} {
+		if _, err := bytecube.NewValidCube(s.reduced(&x)); err == parity.err {
+			seq := notation.MustParse(parity.alg)
+			p := moveSequencePerm(seq)
+			p.apply(&x)
+			solution = append(solution, seq...)
+		}
+	}
+	cube, err := bytecube.NewValidCube(s.reduced(&x))
+	if err != nil {
+		return "", ErrNotReduced
+	}
+	threeByThree, err := solver.NewContext(ctx, s.name, cube, s.options)
+	if err != nil {
+		return "", err
+	}
+	//the 3x3 solution is only part of the solution so it isn't passed on
+	threeByThree.SetObserver(func(e solver.Event) {
+		if e.Kind != solver.SolutionFound {
+			s.observer.Notify(e)
+		}
+	})
+	faceTurns, err := threeByThree.SolveContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	seq, err := notation.Parse(faceTurns)
+	if err != nil {
+		return "", err
+	}
+	solution = algorithm.Simplify(append(solution, seq...))
+	result := solution.String()
+	s.observer.Notify(solver.Event{Kind: solver.SolutionFound, Depth: len(solution), Solution: result})
+	return result, nil
+}
+
+//wingHome returns the position the wing in position w belongs in, -1 when no wing has its colors
+func (s *Solver) wingHome(x *[N_STICKER]byte, w int) int {
+	a, b := x[wingStickers[w][0]], x[wingStickers[w][1]]
+	for home, stickers := range wingStickers {
+		if s.faceColor[stickers[0]/(SIZE*SIZE)] == a && s.faceColor[stickers[1]/(SIZE*SIZE)] == b {
+			return home
+		}
+	}
+	return -1
+}
+
+//centersSolved returns the number of centers that are the color of their side
+func (s *Solver) centersSolved(x *[N_STICKER]byte) int {
+	n := 0
+	for _, sticker := range centerStickers {
+		if x[sticker] == s.faceColor[sticker/(SIZE*SIZE)] {
+			n++
+		}
+	}
+	return n
+}
+
+//pairedEdges returns twice the number of edges whose wings show the same colors on both sides
+func pairedEdges(x *[N_STICKER]byte) int {
+	var colors [N_WING / 2][2][2]byte
+	var seen [N_WING / 2]bool
+	n := 0
+	for w, stickers := range wingStickers {
+		e := wingEdge[w]
+		//the second wing of an edge has its stickers in the other order
+		pair := [2][2]byte{{x[stickers[0]], x[stickers[1]]}, {x[stickers[1]], x[stickers[0]]}}
+		if !seen[e] {
+			seen[e] = true
+			colors[e] = pair
+		} else if colors[e][0] == pair[1] {
+			n += 2
+		}
+	}
+	return n
+}
+
+//cycleCenters moves the centers of the 3-cycle
+func cycleCenters(x *[N_STICKER]byte, slots [3]int8) {
+	a, b, c := centerStickers[slots[0]], centerStickers[slots[1]], centerStickers[slots[2]]
+	x[a], x[b], x[c] = x[c], x[a], x[b]
+}
+
+//cycleWings moves the wings of the 3-cycle
+func cycleWings(x *[N_STICKER]byte, slots [3]int8) {
+	for i := 0; i < 2; i++ {
+		a, b, c := wingStickers[slots[0]][i], wingStickers[slots[1]][i], wingStickers[slots[2]][i]
+		x[a], x[b], x[c] = x[c], x[a], x[b]
+	}
+}
+
+//reduced returns the 3x3 given by the corners, one wing of each edge and the colors of the sides of a reduced cube
+func (s *Solver) reduced(x *[N_STICKER]byte) string {
+	var b strings.Builder
+	for side := 0; side < 6; side++ {
+		at := func(spot int) byte { return x[side*SIZE*SIZE+spot] }
+		b.WriteByte(at(cornerSpots[0]))
+		b.WriteByte(at(edgeSpots[0]))
+		b.WriteByte(at(cornerSpots[1]))
+		b.WriteByte(at(edgeSpots[1]))
+		b.WriteByte(s.faceColor[side])
+		b.WriteByte(at(edgeSpots[2]))
+		b.WriteByte(at(cornerSpots[2]))
+		b.WriteByte(at(edgeSpots[3]))
+		b.WriteByte(at(cornerSpots[3]))
+	}
+	return b.String()
+}
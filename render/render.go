@@ -0,0 +1,113 @@
+package render
+
+import (
+	"errors"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"strconv"
+	"strings"
+)
+
+//render draws a cube as an unfolded net with the top above the front and the bottom below it:
+//
+//	      U U U
+//	      U U U
+//	      U U U
+//	L L L F F F R R R B B B
+//	L L L F F F R R R B B B
+//	L L L F F F R R R B B B
+//	      D D D
+//	      D D D
+//	      D D D
+
+var ErrBadScheme = errors.New("A scheme is six colors from 0 to 255 separated by commas")
+
+//Scheme holds the ANSI 256 color code of each color number 0 to 5
+type Scheme [6]int
+
+//DEFAULT_SCHEME colors the solved state of the prompt's example with green in front and white on top
+var DEFAULT_SCHEME = Scheme{28, 208, 21, 196, 15, 226}
+
+//netSides holds the bytecube side drawn in each block of the net, -1 where the net is empty
+var netSides = [3][4]int{
+	{-1, 4, -1, -1},
+	{1, 0, 3, 2},
+	{-1, 5, -1, -1},
+}
+
+//grid returns the color of each sticker of the net, -1 where the net is empty
+func grid(c *bytecube.Cube) [9][12]int {
+	s := c.String()
+	var g [9][12]int
+	for br, blocks := range netSides {
+		for bc, side := range blocks {
+			for r := 0; r < 3; r++ {
+				for col := 0; col < 3; col++ {
+					v := -1
+					if side >= 0 {
+						v = int(s[9*side+3*r+col] - '0')
+					}
+					g[3*br+r][3*bc+col] = v
+				}
+			}
+		}
+	}
+	return g
+}
+
+//ASCII returns the net of c with the color number of each sticker
+func ASCII(c *bytecube.Cube) string {
+	var b strings.Builder
+	for _, row := range grid(c) {
+		line := ""
+		for col, v := range row {
+			if col > 0 {
+				line += " "
+			}
+			if v < 0 {
+				line += " "
+			} else {
+				line += strconv.Itoa(v)
+			}
+		}
+		b.WriteString(strings.TrimRight(line, " "))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+//ANSI returns the net of c as blocks colored with scheme for a terminal that shows 256 colors
+func ANSI(c *bytecube.Cube, scheme Scheme) string {
+	var b strings.Builder
+	for _, row := range grid(c) {
+		last := len(row) - 1
+		for last >= 0 && row[last] < 0 {
+			last--
+		}
+		for _, v := range row[:last+1] {
+			if v < 0 || v > 5 {
+				b.WriteString("  ")
+				continue
+			}
+			b.WriteString("\x1b[48;5;" + strconv.Itoa(scheme[v]) + "m  \x1b[0m")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+//ParseScheme reads a scheme written as six color codes separated by commas, such as 28,208,21,196,15,226
+func ParseScheme(s string) (Scheme, error) {
+	var scheme Scheme
+	fields := strings.Split(s, ",")
+	if len(fields) != 6 {
+		return scheme, ErrBadScheme
+	}
+	for i, x := range fields {
+		n, err := strconv.Atoi(strings.TrimSpace(x))
+		if err != nil || n < 0 || n > 255 {
+			return scheme, ErrBadScheme
+		}
+		scheme[i] = n
+	}
+	return scheme, nil
+}
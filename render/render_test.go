@@ -0,0 +1,139 @@
+package render
+
+import (
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/notation"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"strings"
+	"testing"
+)
+
+func TestASCII(t *testing.T) {
+	c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+	rubikscuberunner.NewOfficialRunner(c).Run("R")
+	expected := `      4 4 0
+      4 4 0
+      4 4 0
+1 1 1 0 0 5 3 3 3 4 2 2
+1 1 1 0 0 5 3 3 3 4 2 2
+1 1 1 0 0 5 3 3 3 4 2 2
+      5 5 2
+      5 5 2
+      5 5 2
+`
+	if got := ASCII(c); got != expected {
+		t.Error("Failed ASCII got:\n", got, " expected:\n", expected)
+	}
+}
+
+func TestANSI(t *testing.T) {
+	c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+	got := ANSI(c, DEFAULT_SCHEME)
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != 9 {
+		t.Fatal("Failed ANSI got ", len(lines), " lines")
+	}
+	if strings.Count(lines[0], "\x1b[48;5;15m") != 3 || !strings.HasPrefix(lines[0], "      \x1b[") {
+		t.Error("Failed ANSI top row got: ", lines[0])
+	}
+	if strings.Count(lines[4], "\x1b[48;5;") != 12 || strings.Count(lines[4], "\x1b[48;5;208m") != 3 {
+		t.Error("Failed ANSI middle row got: ", lines[4])
+	}
+}
+
+func TestParseScheme(t *testing.T) {
+	scheme, err := ParseScheme("28, 208,21,196,15,226")
+	if err != nil || scheme != DEFAULT_SCHEME {
+		t.Error("Failed ParseScheme got: ", scheme, err)
+	}
+	for _, x := range []string{"1,2,3", "1,2,3,4,5,256", "a,b,c,d,e,f"} {
+		if _, err := ParseScheme(x); err != ErrBadScheme {
+			t.Error("Failed ParseScheme for ", x, " got: ", err)
+		}
+	}
+}
+
+func TestSVG(t *testing.T) {
+	c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+	rubikscuberunner.NewOfficialRunner(c).Run("R")
+	got := SVG(c, SVGOptions{})
+	if !strings.HasPrefix(got, `<svg xmlns="http://www.w3.org/2000/svg" width="241" height="181"`) {
+		t.Error("Failed SVG header got: ", got[:strings.Index(got, "\n")])
+	}
+	if n := strings.Count(got, "<rect"); n != 54 {
+		t.Error("Failed SVG got ", n, " stickers")
+	}
+	//after R the right column of the top is the front's green
+	if !strings.Contains(got, `<rect x="100.5" y="0.5" width="20" height="20" fill="#009b48"`) {
+		t.Error("Failed SVG top right sticker")
+	}
+	//six of the top's stickers and one of the back's top row are white after R
+	got = SVG(c, SVGOptions{Palette: Palette{"a", "b", "c", "d", "e", "f"}, Mask: LAST_LAYER, Size: 10})
+	if n := strings.Count(got, `fill="`+MASK_COLOR+`"`); n != 54-21 {
+		t.Error("Failed SVG mask got ", n, " gray stickers")
+	}
+	if n := strings.Count(got, `fill="e"`); n != 7 {
+		t.Error("Failed SVG palette got ", n, " top stickers")
+	}
+}
+
+func TestIsometric(t *testing.T) {
+	c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+	got := SVG(c, SVGOptions{View: Isometric})
+	if n := strings.Count(got, "<polygon"); n != 27 {
+		t.Error("Failed Isometric got ", n, " stickers")
+	}
+	for _, color := range []string{DEFAULT_PALETTE[0], DEFAULT_PALETTE[3], DEFAULT_PALETTE[4]} {
+		if n := strings.Count(got, `fill="`+color+`"`); n != 9 {
+			t.Error("Failed Isometric got ", n, " stickers of ", color)
+		}
+	}
+	//the front right corner of the top is in the middle of the image
+	if !strings.Contains(got, "52.96,61") {
+		t.Error("Failed Isometric corner got: ", got)
+	}
+}
+
+func TestSVGSteps(t *testing.T) {
+	c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+	got := SVGSteps(c, notation.MustParse("R U R' U'"), SVGOptions{})
+	if n := strings.Count(got, "<g "); n != 5 {
+		t.Error("Failed SVGSteps got ", n, " steps")
+	}
+	if !strings.Contains(got, `text-anchor="middle">U'</text>`) {
+		t.Error("Failed SVGSteps labels")
+	}
+	if !c.Solved() {
+		t.Error("Failed SVGSteps changed the cube")
+	}
+	last := got[strings.LastIndex(got, "<g "):]
+	want, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+	rubikscuberunner.NewOfficialRunner(want).Run("R U R' U'")
+	if !strings.Contains(SVGSteps(want, nil, SVGOptions{}), last[strings.Index(last, "\n"):strings.Index(last, "<text")]) {
+		t.Error("Failed SVGSteps last step")
+	}
+}
+
+func TestParseMask(t *testing.T) {
+	m, err := ParseMask(strings.Repeat(".", 45) + strings.Repeat("x", 9))
+	if err != nil || !m[45] || m[44] {
+		t.Error("Failed ParseMask got: ", m, err)
+	}
+	for _, x := range []string{"x", strings.Repeat("o", 54)} {
+		if _, err := ParseMask(x); err != ErrBadMask {
+			t.Error("Failed ParseMask for ", x, " got: ", err)
+		}
+	}
+}
+
+func TestParsePalette(t *testing.T) {
+	p, err := ParsePalette("green, orange,blue,red,white,#ff0")
+	if err != nil || p != (Palette{"green", "orange", "blue", "red", "white", "#ff0"}) {
+		t.Error("Failed ParsePalette got: ", p, err)
+	}
+	for _, x := range []string{"a,b", "a,b,c,d,e,", `a,b,c,d,e,"f`} {
+		if _, err := ParsePalette(x); err != ErrBadPalette {
+			t.Error("Failed ParsePalette for ", x, " got: ", err)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package render
+
+import (
+	"errors"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/notation"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrBadPalette = errors.New("A palette is six colors separated by commas")
+var ErrBadMask = errors.New("A mask is 54 characters, x for a gray sticker and . for a colored one")
+
+//Palette holds the SVG color of each color number 0 to 5, such as #ffffff or white
+type Palette [6]string
+
+//DEFAULT_PALETTE uses the same colors as DEFAULT_SCHEME
+var DEFAULT_PALETTE = Palette{"#009b48", "#ff5800", "#0046ad", "#b71234", "#ffffff", "#ffd500"}
+
+//MASK_COLOR is the color of a masked sticker
+const MASK_COLOR = "#808080"
+
+//STICKER_SIZE is the width of a sticker of the net in pixels
+const STICKER_SIZE = 20
+
+//Mask holds the stickers drawn gray in the order of a state string, the zero mask hides nothing
+type Mask [54]bool
+
+//LAST_LAYER masks everything but the top layer for OLL and PLL diagrams
+var LAST_LAYER = lastLayer()
+
+func lastLayer() Mask {
+	var m Mask
+	for i := range m {
+		side, spot := i/9, i%9
+		m[i] = side == 5 || (side < 4 && spot > 2)
+	}
+	return m
+}
+
+//ParseMask reads a mask written as 54 characters in the order of a state, x for a gray sticker and . for a colored one
+func ParseMask(s string) (Mask, error) {
+	var m Mask
+	if len(s) != len(m) {
+		return m, ErrBadMask
+	}
+	for i := range m {
+		switch s[i] {
+		case 'x':
+			m[i] = true
+		case '.':
+		default:
+			return m, ErrBadMask
+		}
+	}
+	return m, nil
+}
+
+//ParsePalette reads a palette written as six colors separated by commas
+func ParsePalette(s string) (Palette, error) {
+	var p Palette
+	fields := strings.Split(s, ",")
+	if len(fields) != len(p) {
+		return p, ErrBadPalette
+	}
+	for i, x := range fields {
+		x = strings.TrimSpace(x)
+		if x == "" || strings.ContainsAny(x, "\"<>&") {
+			return p, ErrBadPalette
+		}
+		p[i] = x
+	}
+	return p, nil
+}
+
+//View is the way a cube is drawn
+type View int
+
+const (
+	//Net is the unfolded net drawn by ASCII
+	Net View = iota
+	//Isometric shows the top, front and right sides as a solid cube
+	Isometric
+)
+
+//SVGOptions holds the settings of an SVG image, zero values mean the default
+type SVGOptions struct {
+	View    View
+	Palette Palette
+	Mask    Mask
+	//Size is the width of a sticker in pixels
+	Size int
+}
+
+//fill returns the color of the sticker at i of the state s
+func (o SVGOptions) fill(s string, i int) string {
+	if o.Mask[i] {
+		return MASK_COLOR
+	}
+	v := int(s[i] - '0')
+	if v < 0 || v > 5 {
+		return MASK_COLOR
+	}
+	if o.Palette[v] != "" {
+		return o.Palette[v]
+	}
+	return DEFAULT_PALETTE[v]
+}
+
+func (o SVGOptions) size() float64 {
+	if o.Size > 0 {
+		return float64(o.Size)
+	}
+	return STICKER_SIZE
+}
+
+//drawing is the body of an SVG image and its size
+type drawing struct {
+	body          strings.Builder
+	width, height float64
+}
+
+//SVG returns an image of c drawn the way o gives
+func SVG(c *bytecube.Cube, o SVGOptions) string {
+	d := draw(c.String(), o)
+	return document(d.width, d.height, d.body.String())
+}
+
+//SVGSteps returns a strip of images of c and of the state after each move of seq with the move written under it,
+//as in algorithm sheets. The cube is drawn with its centers in place, so a whole cube rotation leaves a step unchanged.
+//c is not changed.
+func SVGSteps(c *bytecube.Cube, seq notation.Sequence, o SVGOptions) string {
+	const gap = 10
+	labelHeight := o.size()
+	var body strings.Builder
+	width, height := 0.0, 0.0
+	for i := 0; i <= len(seq); i++ {
+		//each step is drawn from c so moves after a rotation turn the faces that were rotated to their place
+		step := bytecube.NewWithState(c.State())
+		rubikscuberunner.NewOfficialRunner(step).RunSequence(seq[:i])
+		d := draw(step.String(), o)
+		if i > 0 {
+			width += gap
+		}
+		label := ""
+		if i > 0 {
+			label = seq[i-1].String()
+		}
+		body.WriteString(`<g transform="translate(` + num(width) + `,0)">` + "\n")
+		body.WriteString(d.body.String())
+		body.WriteString(`<text x="` + num(d.width/2) + `" y="` + num(d.height+labelHeight*0.8) + `" font-family="sans-serif" font-size="` +
+			num(labelHeight*0.8) + `" text-anchor="middle">` + label + "</text>\n")
+		body.WriteString("</g>\n")
+		width += d.width
+		height = math.Max(height, d.height+labelHeight)
+	}
+	return document(width, height, body.String())
+}
+
+func document(width, height float64, body string) string {
+	return `<svg xmlns="http://www.w3.org/2000/svg" width="` + num(width) + `" height="` + num(height) +
+		`" viewBox="0 0 ` + num(width) + " " + num(height) + `">` + "\n" + body + "</svg>\n"
+}
+
+func draw(s string, o SVGOptions) *drawing {
+	if o.View == Isometric {
+		return isometric(s, o)
+	}
+	return net(s, o)
+}
+
+//net draws every side of the state s in the layout of ASCII
+func net(s string, o SVGOptions) *drawing {
+	size := o.size()
+	d := &drawing{width: 12*size + 1, height: 9*size + 1}
+	for br, blocks := range netSides {
+		for bc, side := range blocks {
+			if side < 0 {
+				continue
+			}
+			for spot := 0; spot < 9; spot++ {
+				x := float64(3*bc+spot%3)*size + 0.5
+				y := float64(3*br+spot/3)*size + 0.5
+				d.body.WriteString(`<rect x="` + num(x) + `" y="` + num(y) + `" width="` + num(size) + `" height="` + num(size) +
+					`" fill="` + o.fill(s, 9*side+spot) + `" stroke="#000"/>` + "\n")
+			}
+		}
+	}
+	return d
+}
+
+//isometricSides holds for the top, front and right sides the corner of spot 0 and the directions of its columns and rows.
+//Positions are those of bytecube's symmetries, the cube's center at 0 and its stickers 2 apart.
+var isometricSides = []struct {
+	side        int
+	corner      [3]float64
+	column, row [3]float64
+}{
+	{4, [3]float64{-3, 3, -3}, [3]float64{1, 0, 0}, [3]float64{0, 0, 1}},
+	{0, [3]float64{-3, 3, 3}, [3]float64{1, 0, 0}, [3]float64{0, -1, 0}},
+	{3, [3]float64{3, 3, 3}, [3]float64{0, 0, -1}, [3]float64{0, -1, 0}},
+}
+
+//isometric draws the top, front and right sides of the state s seen from above the front right corner
+func isometric(s string, o SVGOptions) *drawing {
+	scale := o.size() / 2
+	//the cube's corners project to 6 units either side of the middle across and down
+	cos30 := math.Sqrt(3) / 2
+	d := &drawing{width: 12*cos30*scale + 2, height: 12*scale + 2}
+	project := func(p [3]float64) string {
+		x := (p[0]-p[2])*cos30*scale + d.width/2
+		y := ((p[0]+p[2])/2-p[1])*scale + d.height/2
+		return num(x) + "," + num(y)
+	}
+	for _, side := range isometricSides {
+		for spot := 0; spot < 9; spot++ {
+			r, c := float64(2*(spot/3)), float64(2*(spot%3))
+			var points []string
+			for _, offset := range [][2]float64{{0, 0}, {0, 2}, {2, 2}, {2, 0}} {
+				var p [3]float64
+				for i := range p {
+					p[i] = side.corner[i] + side.row[i]*(r+offset[0]) + side.column[i]*(c+offset[1])
+				}
+				points = append(points, project(p))
+			}
+			d.body.WriteString(`<polygon points="` + strings.Join(points, " ") + `" fill="` + o.fill(s, 9*side.side+spot) +
+				`" stroke="#000" stroke-linejoin="round"/>` + "\n")
+		}
+	}
+	return d
+}
+
+//num writes a coordinate with at most two decimal places
+func num(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 2, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
@@ -0,0 +1,214 @@
+package repl
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/format"
+	"github.com/davidafox/rubikscubesolver/render"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"github.com/davidafox/rubikscubesolver/solver"
+	"io"
+	"strings"
+	"time"
+)
+
+//repl holds a cube that is turned a line of moves at a time, with commands to look at it, undo and redo changes
+//and solve it from where it is. Any line that isn't a command is read as moves in official notation.
+
+var ErrQuit = errors.New("Quit")
+var ErrNothingToUndo = errors.New("There is nothing to undo")
+var ErrNothingToRedo = errors.New("There is nothing to redo")
+var ErrNoState = errors.New("load needs a state")
+
+//PROMPT is written before each line is read
+const PROMPT = "> "
+
+//HELP is the output of the help command
+const HELP = `Moves such as R U R' U' or [R, U]3 turn the cube.
+show          print the cube
+undo, redo    undo or redo the last change
+reset         go back to a solved cube
+load <state>  set the cube to a state as digits, a Kociemba string or color letters
+validate      check the cube can be solved
+solve         print a solution from here
+hint          print the first move of a solution
+quit          leave
+`
+
+//Config holds the settings of a session
+type Config struct {
+	Solver  string
+	Options solver.Options
+	//Timeout is the longest solve and hint may take, 0 for no limit
+	Timeout time.Duration
+	//Net draws the cube for show, render.ASCII when nil
+	Net func(c *bytecube.Cube) string
+}
+
+//Session is a cube and the states it was in before each change that can be undone
+type Session struct {
+	config Config
+	cube   *bytecube.Cube
+	undo   []bytecube.State
+	redo   []bytecube.State
+}
+
+//New starts a session with c, which it changes
+func New(c *bytecube.Cube, config Config) *Session {
+	s := new(Session)
+	s.config = config
+	s.cube = c
+	if s.config.Net == nil {
+		s.config.Net = render.ASCII
+	}
+	return s
+}
+
+//Cube returns the cube in its current state
+func (s *Session) Cube() *bytecube.Cube {
+	return s.cube
+}
+
+//Execute runs a line and returns what it prints. ErrQuit is returned for quit.
+func (s *Session) Execute(ctx context.Context, line string) (string, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	switch fields[0] {
+	case "help":
+		return HELP, nil
+	case "quit", "exit":
+		return "", ErrQuit
+	case "show":
+		return s.config.Net(s.cube), nil
+	case "undo":
+		return s.undoLast()
+	case "redo":
+		return s.redoLast()
+	case "reset":
+		c, _ := bytecube.NewCube(bytecube.SOLVED)
+		s.change(c.State())
+		return "", nil
+	case "load":
+		if len(fields) != 2 {
+			return "", ErrNoState
+		}
+		c, _, err := format.Parse(fields[1])
+		if err != nil {
+			return "", err
+		}
+		s.change(c.State())
+		return "", nil
+	case "validate":
+		if _, err := s.cube.Validate(); err != nil {
+			return "", err
+		}
+		return "The cube can be solved\n", nil
+	case "solve", "hint":
+		solution, err := s.solve(ctx)
+		if err != nil {
+			return "", err
+		}
+		moves := strings.Fields(solution)
+		if len(moves) == 0 {
+			return "The cube is already solved\n", nil
+		}
+		if fields[0] == "hint" {
+			return moves[0] + "\n", nil
+		}
+		return solution + "\n", nil
+	}
+	return s.turn(line)
+}
+
+//turn applies a line of moves as a single change
+func (s *Session) turn(line string) (string, error) {
+	before := s.cube.State()
+	c := bytecube.NewWithState(before)
+	if err := rubikscuberunner.NewOfficialRunner(c).Run(line); err != nil {
+		return "", err
+	}
+	s.change(c.State())
+	if c.Solved() {
+		return "Solved\n", nil
+	}
+	return "", nil
+}
+
+//change sets the cube to state keeping the state it was in for undo
+func (s *Session) change(state bytecube.State) {
+	s.undo = append(s.undo, s.cube.State())
+	s.redo = s.redo[:0]
+	s.set(state)
+}
+
+func (s *Session) set(state bytecube.State) {
+	*s.cube = *bytecube.NewWithState(state)
+}
+
+func (s *Session) undoLast() (string, error) {
+	if len(s.undo) == 0 {
+		return "", ErrNothingToUndo
+	}
+	s.redo = append(s.redo, s.cube.State())
+	s.set(s.undo[len(s.undo)-1])
+	s.undo = s.undo[:len(s.undo)-1]
+	return "", nil
+}
+
+func (s *Session) redoLast() (string, error) {
+	if len(s.redo) == 0 {
+		return "", ErrNothingToRedo
+	}
+	s.undo = append(s.undo, s.cube.State())
+	s.set(s.redo[len(s.redo)-1])
+	s.redo = s.redo[:len(s.redo)-1]
+	return "", nil
+}
+
+//solve returns a solution from the current state, it has no moves when the cube is already solved
+func (s *Session) solve(ctx context.Context) (string, error) {
+	if _, err := s.cube.Validate(); err != nil {
+		return "", err
+	}
+	if s.cube.Solved() {
+		return "", nil
+	}
+	if s.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancel()
+	}
+	//the solver gets a copy so the session's cube only changes through moves
+	x, err := solver.NewContext(ctx, s.config.Solver, bytecube.NewWithState(s.cube.State()), s.config.Options)
+	if err != nil {
+		return "", err
+	}
+	return x.SolveContext(ctx)
+}
+
+//Run reads lines from r and writes their output and errors to w until quit or the end of r
+func Run(ctx context.Context, s *Session, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	for {
+		if _, err := io.WriteString(w, PROMPT); err != nil {
+			return err
+		}
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+		out, err := s.Execute(ctx, scanner.Text())
+		if err == ErrQuit {
+			return nil
+		}
+		if err != nil {
+			out = err.Error() + "\n"
+		}
+		if _, err := io.WriteString(w, out); err != nil {
+			return err
+		}
+	}
+}
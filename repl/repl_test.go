@@ -0,0 +1,116 @@
+package repl
+
+import (
+	"bytes"
+	"context"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	_ "github.com/davidafox/rubikscubesolver/twophase"
+	"strings"
+	"testing"
+)
+
+func newSession() *Session {
+	c, _ := bytecube.NewCube(bytecube.SOLVED)
+	return New(c, Config{Solver: "twophase"})
+}
+
+func state(moves string) string {
+	c, _ := bytecube.NewCube(bytecube.SOLVED)
+	rubikscuberunner.NewOfficialRunner(c).Run(moves)
+	return c.String()
+}
+
+func TestUndoRedo(t *testing.T) {
+	s := newSession()
+	ctx := context.Background()
+	steps := []struct {
+		line     string
+		expected string
+		err      error
+	}{
+		{"undo", bytecube.SOLVED, ErrNothingToUndo},
+		{"R U", state("R U"), nil},
+		{"F", state("R U F"), nil},
+		{"R2 (", state("R U F"), nil},
+		{"undo", state("R U"), nil},
+		{"undo", bytecube.SOLVED, nil},
+		{"undo", bytecube.SOLVED, ErrNothingToUndo},
+		{"redo", state("R U"), nil},
+		{"reset", bytecube.SOLVED, nil},
+		{"redo", bytecube.SOLVED, ErrNothingToRedo},
+		{"undo", state("R U"), nil},
+		{"redo", bytecube.SOLVED, nil},
+		{"load " + state("B"), state("B"), nil},
+		{"B'", bytecube.SOLVED, nil},
+		{"undo", state("B"), nil},
+	}
+	for i, x := range steps {
+		_, err := s.Execute(ctx, x.line)
+		if x.err != nil && err != x.err {
+			t.Error("Failed step ", i, " ", x.line, " got: ", err, " expected: ", x.err)
+		}
+		if got := s.Cube().String(); got != x.expected {
+			t.Error("Failed step ", i, " ", x.line, " got: ", got, " expected: ", x.expected)
+		}
+	}
+}
+
+func TestCommands(t *testing.T) {
+	s := newSession()
+	ctx := context.Background()
+	if out, _ := s.Execute(ctx, "R U R' U'"); out != "" {
+		t.Error("Failed moves got: ", out)
+	}
+	if out, _ := s.Execute(ctx, "show"); !strings.HasPrefix(out, "      4 4 ") {
+		t.Error("Failed show got: ", out)
+	}
+	if out, err := s.Execute(ctx, "validate"); err != nil || out != "The cube can be solved\n" {
+		t.Error("Failed validate got: ", out, err)
+	}
+	hint, err := s.Execute(ctx, "hint")
+	if err != nil || len(strings.Fields(hint)) != 1 {
+		t.Error("Failed hint got: ", hint, err)
+	}
+	solution, err := s.Execute(ctx, "solve")
+	if err != nil || strings.Fields(solution)[0] != strings.TrimSpace(hint) {
+		t.Error("Failed solve got: ", solution, err)
+	}
+	if out, _ := s.Execute(ctx, solution); out != "Solved\n" {
+		t.Error("Failed solution got: ", out)
+	}
+	if out, _ := s.Execute(ctx, "solve"); out != "The cube is already solved\n" {
+		t.Error("Failed solve solved cube got: ", out)
+	}
+	if _, err := s.Execute(ctx, "load "+bytecube.SOLVED[:53]+"1"); err != nil {
+		t.Error("Failed load of an invalid state got: ", err)
+	}
+	if _, err := s.Execute(ctx, "validate"); err != bytecube.ErrIncorrectColorNumbers {
+		t.Error("Failed validate of an invalid state got: ", err)
+	}
+	if _, err := s.Execute(ctx, "solve"); err != bytecube.ErrIncorrectColorNumbers {
+		t.Error("Failed solve of an invalid state got: ", err)
+	}
+	if _, err := s.Execute(ctx, "load"); err != ErrNoState {
+		t.Error("Failed load without a state got: ", err)
+	}
+	if _, err := s.Execute(ctx, "quit"); err != ErrQuit {
+		t.Error("Failed quit got: ", err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	s := newSession()
+	var out bytes.Buffer
+	err := Run(context.Background(), s, strings.NewReader("R\nQ\nR'\nquit\nU\n"), &out)
+	if err != nil {
+		t.Error("Failed Run got: ", err)
+	}
+	expected := PROMPT + PROMPT + "Unknown move at position 0\n" + PROMPT + "Solved\n" + PROMPT
+	if out.String() != expected {
+		t.Error("Failed Run got: ", out.String(), " expected: ", expected)
+	}
+	if s.Cube().String() != bytecube.SOLVED {
+		t.Error("Failed Run read after quit")
+	}
+}
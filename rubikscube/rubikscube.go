@@ -1,11 +1,16 @@
 package rubikscube
 
 import (
+	"errors"
 	"fmt"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/combined"
 	"math"
 	"strconv"
 )
 
+var ErrNotSize3 = errors.New("Only a size 3 cube has a bytecube state")
+
 type Cube struct {
 	state  string
 	size   int
@@ -28,6 +33,11 @@ func NewCube(state string, size int) *Cube {
 	return c
 }
 
+//NewWithState returns a size 3 cube in the state s
+func NewWithState(s bytecube.State) *Cube {
+	return NewCube(bytecube.NewWithState(s).String(), 3)
+}
+
 func NewCubeSlice(slice [][][]int, size int) *Cube {
 	c := new(Cube)
 	c.state = convertToString(slice)
@@ -143,6 +153,14 @@ func (c *Cube) RotateDown(columns int) {
 	c.state = c.state[:c.sizeSq*1] + newSide1 + c.state[c.sizeSq*2:]
 }
 
+func (c *Cube) rotateLevelClockwise(lvl int) {
+	last := c.getColumn(1, c.size-1-lvl)
+	c.setColumn(1, c.size-1-lvl, c.getRow(5, lvl))
+	c.setRow(5, lvl, reverseString(c.getColumn(3, lvl)))
+	c.setColumn(3, lvl, c.getRow(4, c.size-1-lvl))
+	c.setRow(4, c.size-1-lvl, reverseString(last))
+}
+
 func (c *Cube) RotateClockwise(level int) {
 	side := make([]string, 6)
 	side[0] = rotateSideClockwise(c.getSide(0), c.size)
@@ -163,6 +181,19 @@ func (c *Cube) RotateClockwise(level int) {
 func (c *Cube) getSide(n int) string {
 	return c.state[c.sizeSq*n : c.sizeSq*(n+1)]
 }
+
+func (c *Cube) setSide(n int, side string) {
+	c.state = c.state[:c.sizeSq*n] + side + c.state[c.sizeSq*(n+1):]
+}
+
+func (c *Cube) rotateLevelCounterClockwise(lvl int) {
+	last := c.getColumn(1, c.size-1-lvl)
+	c.setColumn(1, c.size-1-lvl, reverseString(c.getRow(4, c.size-1-lvl)))
+	c.setRow(4, c.size-1-lvl, c.getColumn(3, lvl))
+	c.setColumn(3, lvl, reverseString(c.getRow(5, lvl)))
+	c.setRow(5, lvl, last)
+}
+
 func (c *Cube) RotateCounterClockwise(level int) {
 	side := make([]string, 6)
 	side[0] = rotateSideCounterClockwise(c.getSide(0), c.size)
@@ -181,6 +212,140 @@ func (c *Cube) RotateCounterClockwise(level int) {
 	}
 }
 
+//Separate functions for "official" notation, each turns only the outer layer of any size of cube
+
+func (c *Cube) RotateR() {
+	c.setSide(3, rotateSideClockwise(c.getSide(3), c.size))
+	c.rotateColumnUp(c.size - 1)
+}
+
+func (c *Cube) RotateRCounter() {
+	c.setSide(3, rotateSideCounterClockwise(c.getSide(3), c.size))
+	c.rotateColumnDown(c.size - 1)
+}
+
+func (c *Cube) RotateL() {
+	c.setSide(1, rotateSideClockwise(c.getSide(1), c.size))
+	c.rotateColumnDown(0)
+}
+
+func (c *Cube) RotateLCounter() {
+	c.setSide(1, rotateSideCounterClockwise(c.getSide(1), c.size))
+	c.rotateColumnUp(0)
+}
+
+func (c *Cube) RotateU() {
+	c.setSide(4, rotateSideClockwise(c.getSide(4), c.size))
+	c.rotateRowRight(0)
+}
+
+func (c *Cube) RotateUCounter() {
+	c.setSide(4, rotateSideCounterClockwise(c.getSide(4), c.size))
+	c.rotateRowLeft(0)
+}
+
+func (c *Cube) RotateD() {
+	c.setSide(5, rotateSideClockwise(c.getSide(5), c.size))
+	c.rotateRowLeft(c.size - 1)
+}
+
+func (c *Cube) RotateDCounter() {
+	c.setSide(5, rotateSideCounterClockwise(c.getSide(5), c.size))
+	c.rotateRowRight(c.size - 1)
+}
+
+func (c *Cube) RotateF() {
+	c.setSide(0, rotateSideClockwise(c.getSide(0), c.size))
+	c.rotateLevelClockwise(0)
+}
+
+func (c *Cube) RotateFCounter() {
+	c.setSide(0, rotateSideCounterClockwise(c.getSide(0), c.size))
+	c.rotateLevelCounterClockwise(0)
+}
+
+func (c *Cube) RotateB() {
+	c.setSide(2, rotateSideClockwise(c.getSide(2), c.size))
+	c.rotateLevelCounterClockwise(c.size - 1)
+}
+
+func (c *Cube) RotateBCounter() {
+	c.setSide(2, rotateSideCounterClockwise(c.getSide(2), c.size))
+	c.rotateLevelClockwise(c.size - 1)
+}
+
+//layerSides holds the side of each face letter and of the face opposite it
+var layerSides = map[byte][2]int{'F': {0, 2}, 'L': {1, 3}, 'B': {2, 0}, 'R': {3, 1}, 'U': {4, 5}, 'D': {5, 4}}
+
+//RotateLayers turns the layers first to last of face, one of R L U D F B, clockwise as seen from that face the given number of quarter turns.
+//Layers are counted from the face which is layer 0, so on a 4x4 layers 1 to 1 of R is the inner slice 2R and 0 to 1 is Rw.
+func (c *Cube) RotateLayers(face byte, first, last, quarters int) {
+	sides, ok := layerSides[face]
+	if !ok {
+		return
+	}
+	for ; quarters > 0; quarters-- {
+		for layer := first; layer <= last; layer++ {
+			far := c.size - 1 - layer
+			switch face {
+			case 'R':
+				c.rotateColumnUp(far)
+			case 'L':
+				c.rotateColumnDown(layer)
+			case 'U':
+				c.rotateRowRight(layer)
+			case 'D':
+				c.rotateRowLeft(far)
+			case 'F':
+				c.rotateLevelClockwise(layer)
+			case 'B':
+				c.rotateLevelCounterClockwise(far)
+			}
+			if layer == 0 {
+				c.setSide(sides[0], rotateSideClockwise(c.getSide(sides[0]), c.size))
+			}
+			if far == 0 {
+				c.setSide(sides[1], rotateSideCounterClockwise(c.getSide(sides[1]), c.size))
+			}
+		}
+	}
+}
+
+//State returns the state of a size 3 cube as a bytecube.State, other sizes give ErrNotSize3
+func (c *Cube) State() (bytecube.State, error) {
+	if c.size != 3 {
+		return bytecube.State{}, ErrNotSize3
+	}
+	b, err := bytecube.NewCube(c.state)
+	if err != nil {
+		return bytecube.State{}, err
+	}
+	return b.State(), nil
+}
+
+//searchCube is a size 3 cube so its State can't fail, as a combined.Cube's can't
+type searchCube struct {
+	*Cube
+}
+
+func (c searchCube) State() bytecube.State {
+	s, _ := c.Cube.State()
+	return s
+}
+
+//Factory makes the size 3 cubes the combined solver searches with
+type Factory struct {
+}
+
+func (f *Factory) New(s bytecube.State) combined.Cube {
+	return searchCube{NewWithState(s)}
+}
+
+func NewFactory() *Factory {
+	f := new(Factory)
+	return f
+}
+
 func rotateSideClockwise(side string, size int) string {
 	for i := 0; i < size/2; i++ {
 		tmp := getSideRow(side, i, size)
@@ -1,6 +1,10 @@
 package rubikscube
 
 import (
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/combined"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"math/rand"
 	"strings"
 	"testing"
 )
@@ -742,3 +746,176 @@ func TestRotateSideCounterClockwise(t *testing.T) {
 		}
 	}
 }
+
+//turns holds the official turns of both cubes by the same letter
+var turns = map[string]struct {
+	cube     func(*Cube)
+	bytecube func(*bytecube.Cube)
+}{
+	"R":  {(*Cube).RotateR, (*bytecube.Cube).RotateR},
+	"R'": {(*Cube).RotateRCounter, (*bytecube.Cube).RotateRCounter},
+	"L":  {(*Cube).RotateL, (*bytecube.Cube).RotateL},
+	"L'": {(*Cube).RotateLCounter, (*bytecube.Cube).RotateLCounter},
+	"U":  {(*Cube).RotateU, (*bytecube.Cube).RotateU},
+	"U'": {(*Cube).RotateUCounter, (*bytecube.Cube).RotateUCounter},
+	"D":  {(*Cube).RotateD, (*bytecube.Cube).RotateD},
+	"D'": {(*Cube).RotateDCounter, (*bytecube.Cube).RotateDCounter},
+	"F":  {(*Cube).RotateF, (*bytecube.Cube).RotateF},
+	"F'": {(*Cube).RotateFCounter, (*bytecube.Cube).RotateFCounter},
+	"B":  {(*Cube).RotateB, (*bytecube.Cube).RotateB},
+	"B'": {(*Cube).RotateBCounter, (*bytecube.Cube).RotateBCounter},
+	"r0": {func(c *Cube) { c.RotateRight(0) }, func(c *bytecube.Cube) { c.RotateRight(0) }},
+	"l1": {func(c *Cube) { c.RotateLeft(1) }, func(c *bytecube.Cube) { c.RotateLeft(1) }},
+	"u1": {func(c *Cube) { c.RotateUp(1) }, func(c *bytecube.Cube) { c.RotateUp(1) }},
+	"d0": {func(c *Cube) { c.RotateDown(0) }, func(c *bytecube.Cube) { c.RotateDown(0) }},
+	"c1": {func(c *Cube) { c.RotateClockwise(1) }, func(c *bytecube.Cube) { c.RotateClockwise(1) }},
+	"t0": {func(c *Cube) { c.RotateCounterClockwise(0) }, func(c *bytecube.Cube) { c.RotateCounterClockwise(0) }},
+}
+
+func TestConformance(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	names := make([]string, 0, len(turns))
+	for name := range turns {
+		names = append(names, name)
+	}
+	for i := 0; i < 100; i++ {
+		c := NewCube(convertToString(solvedCube), 3)
+		b, _ := bytecube.NewCube(convertToString(solvedCube))
+		var moves []string
+		for j := 0; j < 25; j++ {
+			name := names[r.Intn(len(names))]
+			moves = append(moves, name)
+			turns[name].cube(c)
+			turns[name].bytecube(b)
+			if c.String() != b.String() {
+				t.Fatal("Failed conformance after ", strings.Join(moves, " "), " got: ", c.String(), " expected: ", b.String())
+			}
+		}
+		if state, err := c.State(); err != nil || state != b.State() || c.Solved() != b.Solved() {
+			t.Error("Failed State after ", strings.Join(moves, " "), " got: ", err)
+		}
+		if NewWithState(b.State()).String() != b.String() {
+			t.Error("Failed NewWithState after ", strings.Join(moves, " "))
+		}
+	}
+}
+
+func TestOfficialLargerCubes(t *testing.T) {
+	for size := 2; size <= 5; size++ {
+		solved := ""
+		for side := 0; side < 6; side++ {
+			solved += strings.Repeat(string(rune('0'+side)), size*size)
+		}
+		c := NewCube(solved, size)
+		//the sexy move has order 6 on every size of cube
+		runner := rubikscuberunner.NewOfficialRunner(c)
+		for i := 0; i < 6; i++ {
+			if err := runner.Run("R U R' U'"); err != nil {
+				t.Fatal(err)
+			}
+			if c.Solved() != (i == 5) {
+				t.Error("Failed size ", size, " after ", i+1, " sexy moves got: ", c.String())
+			}
+		}
+		//an outer turn leaves the second layer in place
+		runner.Run("U")
+		if size > 2 && c.getRow(0, 1) != strings.Repeat("0", size) {
+			t.Error("Failed size ", size, " U moved the second row got: ", c.String())
+		}
+		runner.Run("U' F B L R D")
+		runner.Run("D' R' L' B' F'")
+		if !c.Solved() {
+			t.Error("Failed size ", size, " turns and their inverses got: ", c.String())
+		}
+	}
+}
+
+func TestCombinedFactory(t *testing.T) {
+	c := NewCube(convertToString(solvedCube), 3)
+	rubikscuberunner.NewOfficialRunner(c).Run("R U F' L2")
+	s, err := combined.NewSolver(c.String(), "", NewFactory(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	solution := s.Solve()
+	if err := rubikscuberunner.NewOfficialRunner(c).Run(solution); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Solved() {
+		t.Error("Failed combined solve with rubikscube cubes got: ", solution)
+	}
+}
+
+func TestRotateLayers(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	scramble := func(size int) *Cube {
+		solved := ""
+		for side := 0; side < 6; side++ {
+			solved += strings.Repeat(string(rune('0'+side)), size*size)
+		}
+		c := NewCube(solved, size)
+		for i := 0; i < 30; i++ {
+			c.RotateLayers("RLUDFB"[r.Intn(6)], r.Intn(size), size-1, 1+r.Intn(3))
+		}
+		return c
+	}
+	tests := []struct {
+		face                  byte
+		first, last, quarters int
+		official              func(*Cube)
+	}{
+		{'R', 0, 0, 1, (*Cube).RotateR},
+		{'L', 3, 3, 3, (*Cube).RotateR},
+		{'U', 0, 0, 3, (*Cube).RotateUCounter},
+		{'D', 3, 3, 1, (*Cube).RotateUCounter},
+		{'F', 0, 0, 1, (*Cube).RotateF},
+		{'B', 3, 3, 3, (*Cube).RotateF},
+		{'B', 0, 0, 1, (*Cube).RotateB},
+		{'D', 0, 0, 1, (*Cube).RotateD},
+		{'L', 0, 0, 1, (*Cube).RotateL},
+	}
+	for _, x := range tests {
+		c := scramble(4)
+		expected := NewCube(c.String(), 4)
+		x.official(expected)
+		c.RotateLayers(x.face, x.first, x.last, x.quarters)
+		if c.String() != expected.String() {
+			t.Error("Failed RotateLayers ", string(x.face), x.first, x.last, x.quarters, " got: ", c.String(), " expected: ", expected.String())
+		}
+	}
+	//an inner slice turned one way from each side cancels
+	c := scramble(4)
+	start := c.String()
+	c.RotateLayers('R', 1, 1, 1)
+	c.RotateLayers('L', 2, 2, 1)
+	if c.String() != start {
+		t.Error("Failed RotateLayers inner slices from opposite sides")
+	}
+	//the layer count turns of the front match turning its layers, except that turning every layer doesn't turn the back side
+	for level := 0; level < 3; level++ {
+		c = scramble(4)
+		expected := NewCube(c.String(), 4)
+		expected.RotateLayers('F', 0, level, 1)
+		c.RotateClockwise(level)
+		if c.String() != expected.String() {
+			t.Error("Failed RotateClockwise ", level, " got: ", c.String(), " expected: ", expected.String())
+		}
+		expected.RotateLayers('F', 0, level, 3)
+		c.RotateCounterClockwise(level)
+		if c.String() != expected.String() {
+			t.Error("Failed RotateCounterClockwise ", level, " got: ", c.String(), " expected: ", expected.String())
+		}
+	}
+	//turning every layer moves the whole cube so it stays solved
+	c = NewCube(convertToString(solvedCube), 3)
+	c.RotateLayers('F', 0, 2, 1)
+	if !c.Solved() || c.String() == convertToString(solvedCube) {
+		t.Error("Failed RotateLayers whole cube got: ", c.String())
+	}
+}
+
+func TestStateSize(t *testing.T) {
+	if _, err := NewCube(strings.Repeat("0", 96), 4).State(); err != ErrNotSize3 {
+		t.Error("Failed State of a 4x4 got: ", err, " expected: ", ErrNotSize3)
+	}
+}
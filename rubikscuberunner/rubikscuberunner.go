@@ -1,9 +1,10 @@
 package rubikscuberunner
 
 import (
+	"errors"
 	"fmt"
+	"github.com/davidafox/rubikscubesolver/notation"
 	"strconv"
-	"strings"
 )
 
 //rubikscuberunner interprets a string of commands in letter number pairs
@@ -15,6 +16,9 @@ import (
 //L==RotateLeft()
 //So C1 would result in RotateClockwise(1)
 //and R3 would result in RotateCounterClockwise(3).
+//Official and Legacy convert between these steps and official notation.
+
+var ErrInvalidStep = errors.New("Invalid step")
 
 type cube interface {
 	RotateClockwise(row int)
@@ -25,7 +29,8 @@ type cube interface {
 	RotateLeft(row int)
 }
 
-type officialCube interface {
+//OfficialCube is a cube turned with official face turns
+type OfficialCube interface {
 	RotateR()
 	RotateRCounter()
 	RotateL()
@@ -45,10 +50,10 @@ type Runner struct {
 }
 
 type OfficialRunner struct {
-	c officialCube
+	c OfficialCube
 }
 
-func NewOfficialRunner(c officialCube) *OfficialRunner {
+func NewOfficialRunner(c OfficialCube) *OfficialRunner {
 	r := new(OfficialRunner)
 	r.c = c
 	return r
@@ -60,83 +65,43 @@ func NewRunner(c cube) *Runner {
 	return r
 }
 
-func (r *OfficialRunner) Run(s string) {
-	s = strings.TrimSpace(s)
-	steps := strings.Split(s, " ")
-	for _, step := range steps {
-		switch string(step[0]) {
-		case "R":
-			if len(step) < 2 {
-				r.c.RotateR()
-			} else {
-				if string(step[1]) == "'" {
-					r.c.RotateRCounter()
-				} else {
-					r.c.RotateR()
-					r.c.RotateR()
-				}
-			}
-		case "L":
-			if len(step) < 2 {
-				r.c.RotateL()
-			} else {
-				if string(step[1]) == "'" {
-					r.c.RotateLCounter()
-				} else {
-					r.c.RotateL()
-					r.c.RotateL()
-				}
-			}
-		case "U":
-			if len(step) < 2 {
-				r.c.RotateU()
-			} else {
-				if string(step[1]) == "'" {
-					r.c.RotateUCounter()
-				} else {
-					r.c.RotateU()
-					r.c.RotateU()
-				}
-			}
-		case "D":
-			if len(step) < 2 {
-				r.c.RotateD()
-			} else {
-				if string(step[1]) == "'" {
-					r.c.RotateDCounter()
-				} else {
-					r.c.RotateD()
-					r.c.RotateD()
-				}
-			}
-		case "F":
-			if len(step) < 2 {
-				r.c.RotateF()
-			} else {
-				if string(step[1]) == "'" {
-					r.c.RotateFCounter()
-				} else {
-					r.c.RotateF()
-					r.c.RotateF()
-				}
-			}
-		case "B":
-			if len(step) < 2 {
-				r.c.RotateB()
-			} else {
-				if string(step[1]) == "'" {
-					r.c.RotateBCounter()
-				} else {
-					r.c.RotateB()
-					r.c.RotateB()
-				}
-			}
-		default:
-			fmt.Println("Invalid step: ", s)
+//Run parses s as official notation and applies it, nothing is applied if s doesn't parse.
+//Wide moves, slices and rotations are applied as face turns so the cube may end up rotated.
+func (r *OfficialRunner) Run(s string) error {
+	seq, err := notation.Parse(s)
+	if err != nil {
+		return err
+	}
+	r.RunSequence(seq)
+	return nil
+}
+
+//RunSequence applies a parsed sequence
+func (r *OfficialRunner) RunSequence(seq notation.Sequence) {
+	for _, m := range seq.FaceTurns() {
+		turns := officialTurns[m.Face]
+		switch m.Turns {
+		case 1:
+			turns[0](r.c)
+		case 2:
+			turns[0](r.c)
+			turns[0](r.c)
+		case 3:
+			turns[1](r.c)
 		}
 	}
 }
 
+//officialTurns holds the clockwise and counterclockwise turn of each face
+var officialTurns = map[byte][2]func(OfficialCube){
+	'R': {OfficialCube.RotateR, OfficialCube.RotateRCounter},
+	'L': {OfficialCube.RotateL, OfficialCube.RotateLCounter},
+	'U': {OfficialCube.RotateU, OfficialCube.RotateUCounter},
+	'D': {OfficialCube.RotateD, OfficialCube.RotateDCounter},
+	'F': {OfficialCube.RotateF, OfficialCube.RotateFCounter},
+	'B': {OfficialCube.RotateB, OfficialCube.RotateBCounter},
+}
+
 func (r *Runner) Run(s string) {
 	for i := 0; i+1 < len(s); i += 2 {
 		x, err := strconv.Atoi(string(s[i+1]))
@@ -171,3 +136,86 @@ func (r *Runner) Run(s string) {
 		}
 	}
 }
+
+//legacySteps holds the legacy step for each official move of the layers a legacy step can turn on a 3x3 cube.
+//A step with one row turns two layers so it is a wide move. Two rows would turn the middle layer and the far face's
+//side stickers but not its face, which can't be done to a real cube.
+var legacySteps = map[notation.Move]string{
+	{Face: 'U', Turns: 1}:             "R0",
+	{Face: 'U', Turns: 3}:             "L0",
+	{Face: 'U', Turns: 2}:             "r0",
+	{Face: 'U', Wide: true, Turns: 1}: "R1",
+	{Face: 'U', Wide: true, Turns: 3}: "L1",
+	{Face: 'U', Wide: true, Turns: 2}: "r1",
+	{Face: 'L', Turns: 1}:             "D0",
+	{Face: 'L', Turns: 3}:             "U0",
+	{Face: 'L', Turns: 2}:             "u0",
+	{Face: 'L', Wide: true, Turns: 1}: "D1",
+	{Face: 'L', Wide: true, Turns: 3}: "U1",
+	{Face: 'L', Wide: true, Turns: 2}: "u1",
+	{Face: 'F', Turns: 1}:             "C0",
+	{Face: 'F', Turns: 3}:             "T0",
+	{Face: 'F', Turns: 2}:             "c0",
+	{Face: 'F', Wide: true, Turns: 1}: "C1",
+	{Face: 'F', Wide: true, Turns: 3}: "T1",
+	{Face: 'F', Wide: true, Turns: 2}: "c1",
+}
+
+//officialMoves holds the official move for each legacy step
+var officialMoves = func() map[string]notation.Move {
+	m := make(map[string]notation.Move)
+	for move, step := range legacySteps {
+		m[step] = move
+	}
+	return m
+}()
+
+//opposites holds the face opposite each face
+var opposites = map[byte]byte{'U': 'D', 'D': 'U', 'L': 'R', 'R': 'L', 'F': 'B', 'B': 'F'}
+
+//Official converts the legacy steps of a 3x3 cube into the official moves that turn the same layers
+func Official(legacy string) (notation.Sequence, error) {
+	if len(legacy)%2 != 0 {
+		return nil, ErrInvalidStep
+	}
+	seq := make(notation.Sequence, 0, len(legacy)/2)
+	for i := 0; i+1 < len(legacy); i += 2 {
+		m, ok := officialMoves[legacy[i:i+2]]
+		if !ok {
+			return nil, ErrInvalidStep
+		}
+		seq = append(seq, m)
+	}
+	return seq, nil
+}
+
+//FaceTurns converts the legacy steps of a 3x3 cube, where a step turns one or two layers, into official face turns.
+//The face turns leave the cube in the same state as the legacy steps apart from a whole cube rotation,
+//so a solution stays a solution.
+func FaceTurns(s string) (string, error) {
+	seq, err := Official(s)
+	if err != nil {
+		return "", err
+	}
+	return seq.FaceTurns().String(), nil
+}
+
+//Legacy converts official moves into legacy steps for a 3x3 cube.
+//Legacy steps only turn the U, L and F layers so the D, R and B faces are turned by turning the two layers
+//opposite them, which rotates the cube, and the moves after that are made in the rotated cube.
+//The legacy steps leave the cube in the same state as seq apart from a whole cube rotation.
+func Legacy(seq notation.Sequence) string {
+	frame := notation.NewFrame()
+	steps := ""
+	for _, m := range seq.FaceTurns() {
+		position := frame.Position(m.Face)
+		if step, ok := legacySteps[notation.Move{Face: position, Turns: m.Turns}]; ok {
+			steps += step
+			continue
+		}
+		wide := notation.Move{Face: opposites[position], Wide: true, Turns: m.Turns}
+		steps += legacySteps[wide]
+		frame.Rotate(wide.Rotation())
+	}
+	return steps
+}
@@ -1,6 +1,10 @@
 package rubikscuberunner
 
 import (
+	"errors"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/notation"
+	"math/rand"
 	"strconv"
 	"testing"
 )
@@ -49,3 +53,213 @@ func TestRun(t *testing.T) {
 		}
 	}
 }
+
+//orientations turn a cube to each of its 24 orientations, a whole cube rotation is two layers and the third face
+var orientations = func() []func(c *bytecube.Cube) {
+	x := func(c *bytecube.Cube) { c.RotateUp(1); c.RotateR() }
+	y := func(c *bytecube.Cube) { c.RotateRight(1); c.RotateDCounter() }
+	z := func(c *bytecube.Cube) { c.RotateClockwise(1); c.RotateBCounter() }
+	var result []func(c *bytecube.Cube)
+	for _, up := range [][]func(c *bytecube.Cube){{}, {x}, {x, x}, {x, x, x}, {z}, {z, z, z}} {
+		for turns := 0; turns < 4; turns++ {
+			up, turns := up, turns
+			result = append(result, func(c *bytecube.Cube) {
+				for _, f := range up {
+					f(c)
+				}
+				for i := 0; i < turns; i++ {
+					y(c)
+				}
+			})
+		}
+	}
+	return result
+}()
+
+func TestFaceTurns(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	letters := "RLUDCTruc"
+	for i := 0; i < 200; i++ {
+		legacy := ""
+		for j := 0; j < 8; j++ {
+			legacy += string(letters[rng.Intn(len(letters))]) + strconv.Itoa(rng.Intn(2))
+		}
+		official, err := FaceTurns(legacy)
+		if err != nil {
+			t.Error("Failed FaceTurns for ", legacy, " got error: ", err)
+			continue
+		}
+		a, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+		NewRunner(a).Run(legacy)
+		b, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+		if official != "" {
+			NewOfficialRunner(b).Run(official)
+		}
+		same := false
+		for _, orient := range orientations {
+			c := bytecube.NewWithState(a.State())
+			orient(c)
+			same = same || c.String() == b.String()
+		}
+		if !same {
+			t.Error("Failed FaceTurns for ", legacy, " got: ", official)
+		}
+	}
+	for _, x := range []string{"R", "X0", "R2", "Ra"} {
+		if _, err := FaceTurns(x); err != ErrInvalidStep {
+			t.Error("Failed FaceTurns for ", x, " got: ", err, " expected: ", ErrInvalidStep)
+		}
+	}
+}
+
+//physical turns the layers of each move directly, the legacy steps with one row turn two layers
+var physical = func() map[string]func(c *bytecube.Cube) {
+	m := map[string]func(c *bytecube.Cube){
+		"U":  (*bytecube.Cube).RotateU,
+		"D":  (*bytecube.Cube).RotateD,
+		"R":  (*bytecube.Cube).RotateR,
+		"L":  (*bytecube.Cube).RotateL,
+		"F":  (*bytecube.Cube).RotateF,
+		"B":  (*bytecube.Cube).RotateB,
+		"Uw": func(c *bytecube.Cube) { c.RotateRight(1) },
+		"Lw": func(c *bytecube.Cube) { c.RotateDown(1) },
+		"Fw": func(c *bytecube.Cube) { c.RotateClockwise(1) },
+		"x":  func(c *bytecube.Cube) { c.RotateUp(1); c.RotateR() },
+		"y":  func(c *bytecube.Cube) { c.RotateRight(1); c.RotateDCounter() },
+		"z":  func(c *bytecube.Cube) { c.RotateClockwise(1); c.RotateBCounter() },
+	}
+	times := func(f func(c *bytecube.Cube), n int) func(c *bytecube.Cube) {
+		return func(c *bytecube.Cube) {
+			for i := 0; i < n; i++ {
+				f(c)
+			}
+		}
+	}
+	m["Rw"] = func(c *bytecube.Cube) { c.RotateL(); m["x"](c) }
+	m["Dw"] = func(c *bytecube.Cube) { c.RotateU(); times(m["y"], 3)(c) }
+	m["Bw"] = func(c *bytecube.Cube) { c.RotateF(); times(m["z"], 3)(c) }
+	m["M"] = func(c *bytecube.Cube) { m["Lw"](c); c.RotateLCounter() }
+	m["E"] = func(c *bytecube.Cube) { c.RotateU(); times(m["Uw"], 3)(c) }
+	m["S"] = func(c *bytecube.Cube) { m["Fw"](c); c.RotateFCounter() }
+	return m
+}()
+
+func TestRunSequence(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	moves := []string{"U", "D", "R", "L", "F", "B", "Uw", "Dw", "Rw", "Lw", "Fw", "Bw", "M", "E", "S", "x", "y", "z"}
+	for i := 0; i < 200; i++ {
+		seq := ""
+		for j := 0; j < 8; j++ {
+			seq += moves[rng.Intn(len(moves))] + []string{"", "2", "'"}[rng.Intn(3)] + " "
+		}
+		a, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+		for _, m := range notation.MustParse(seq) {
+			name := string(m.Face)
+			if m.Wide {
+				name += "w"
+			}
+			for k := 0; k < m.Turns; k++ {
+				physical[name](a)
+			}
+		}
+		b, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+		if err := NewOfficialRunner(b).Run(seq); err != nil {
+			t.Error("Failed Run for ", seq, " got error: ", err)
+			continue
+		}
+		same := false
+		for _, orient := range orientations {
+			c := bytecube.NewWithState(b.State())
+			orient(c)
+			same = same || c.String() == a.String()
+		}
+		if !same {
+			t.Error("Failed Run for ", seq, " got: ", notation.MustParse(seq).FaceTurns().String())
+		}
+	}
+}
+
+func TestOfficialRunError(t *testing.T) {
+	c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+	for _, x := range []string{"R  U", " R U ", "R\tU\n"} {
+		if err := NewOfficialRunner(c).Run(x); err != nil {
+			t.Error("Failed Run for ", x, " got error: ", err)
+		}
+	}
+	before := c.String()
+	err := NewOfficialRunner(c).Run("R U Q")
+	if !errors.Is(err, notation.ErrUnknownMove) {
+		t.Error("Failed Run got: ", err, " expected: ", notation.ErrUnknownMove)
+	}
+	if c.String() != before {
+		t.Error("Failed Run applied moves before an error")
+	}
+}
+
+func TestOfficial(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	letters := "RLUDCTruc"
+	for i := 0; i < 200; i++ {
+		legacy := ""
+		for j := 0; j < 8; j++ {
+			legacy += string(letters[rng.Intn(len(letters))]) + strconv.Itoa(rng.Intn(2))
+		}
+		seq, err := Official(legacy)
+		if err != nil {
+			t.Error("Failed Official for ", legacy, " got error: ", err)
+			continue
+		}
+		a, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+		NewRunner(a).Run(legacy)
+		//wide moves turn the same layers as the legacy steps so the cubes are the same without rotating
+		b, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+		for _, m := range seq {
+			name := string(m.Face)
+			if m.Wide {
+				name += "w"
+			}
+			for k := 0; k < m.Turns; k++ {
+				physical[name](b)
+			}
+		}
+		if a.String() != b.String() {
+			t.Error("Failed Official for ", legacy, " got: ", seq)
+		}
+	}
+	if seq, _ := Official("R1D0c1"); seq.String() != "Uw L Fw2" {
+		t.Error("Failed Official for R1D0c1 got: ", seq)
+	}
+	for _, x := range []string{"R", "X0", "R2", "Ra"} {
+		if _, err := Official(x); err != ErrInvalidStep {
+			t.Error("Failed Official for ", x, " got: ", err, " expected: ", ErrInvalidStep)
+		}
+	}
+}
+
+func TestLegacy(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	moves := []string{"U", "D", "R", "L", "F", "B", "Uw", "Dw", "Rw", "Lw", "Fw", "Bw", "M", "E", "S", "x", "y", "z"}
+	for i := 0; i < 200; i++ {
+		s := ""
+		for j := 0; j < 8; j++ {
+			s += moves[rng.Intn(len(moves))] + []string{"", "2", "'"}[rng.Intn(3)] + " "
+		}
+		legacy := Legacy(notation.MustParse(s))
+		a, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+		NewRunner(a).Run(legacy)
+		b, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+		NewOfficialRunner(b).Run(s)
+		same := false
+		for _, orient := range orientations {
+			c := bytecube.NewWithState(a.State())
+			orient(c)
+			same = same || c.String() == b.String()
+		}
+		if !same {
+			t.Error("Failed Legacy for ", s, " got: ", legacy)
+		}
+	}
+	if legacy := Legacy(notation.MustParse("U L' F2 D R")); legacy != "R0U0c0R1C0" {
+		t.Error("Failed Legacy got: ", legacy)
+	}
+}
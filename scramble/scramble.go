@@ -0,0 +1,78 @@
+package scramble
+
+import (
+	"context"
+	"github.com/davidafox/rubikscubesolver/algorithm"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/cubie"
+	"github.com/davidafox/rubikscubesolver/notation"
+	"github.com/davidafox/rubikscubesolver/solver"
+	_ "github.com/davidafox/rubikscubesolver/twophase"
+	"math/rand"
+)
+
+//scramble makes random state scrambles like the ones used in competitions.
+//Every legal position of the cube is equally likely and the scramble is the inverse of a solution to it.
+
+//DEFAULT_SOLVER is used to find the scrambles, it is fast and the scrambles are 23 moves at most
+const DEFAULT_SOLVER = "twophase"
+
+//Scramble is a random cube and the moves that turn a solved cube into it
+type Scramble struct {
+	Cube  *bytecube.Cube
+	Moves string
+}
+
+//Generator makes scrambles from its own random numbers so the same seed gives the same scrambles
+type Generator struct {
+	rng     *rand.Rand
+	solver  string
+	options solver.Options
+}
+
+//NewGenerator returns a generator using the default solver seeded with seed
+func NewGenerator(seed int64) *Generator {
+	g := new(Generator)
+	g.rng = rand.New(rand.NewSource(seed))
+	g.solver = DEFAULT_SOLVER
+	return g
+}
+
+//SetSolver sets the registered solver used to find the scrambles
+func (g *Generator) SetSolver(name string, o solver.Options) {
+	g.solver = name
+	g.options = o
+}
+
+//RandomCube returns a random legal cube, every legal cube is equally likely
+func (g *Generator) RandomCube() *cubie.Cube {
+	c := cubie.NewCube()
+	copy(c.CornerPerm[:], g.rng.Perm(8))
+	copy(c.EdgePerm[:], g.rng.Perm(12))
+	//half of the permutations can't be reached, swapping two edges of those gives every reachable one once
+	if cubie.Parity(c.CornerPerm[:]) != cubie.Parity(c.EdgePerm[:]) {
+		c.EdgePerm[10], c.EdgePerm[11] = c.EdgePerm[11], c.EdgePerm[10]
+	}
+	c.SetTwist(g.rng.Intn(2187))
+	c.SetFlip(g.rng.Intn(2048))
+	return c
+}
+
+//Next returns a new scramble, ctx stops the solver
+func (g *Generator) Next(ctx context.Context) (*Scramble, error) {
+	c := g.RandomCube().Bytecube()
+	s, err := solver.NewContext(ctx, g.solver, c, g.options)
+	if err != nil {
+		return nil, err
+	}
+	solution, err := s.SolveContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seq, err := notation.Parse(solution)
+	if err != nil {
+		return nil, err
+	}
+	//inverting reverses the order of turns of opposite faces so they are put back in the usual order
+	return &Scramble{c, algorithm.Simplify(seq.Inverse()).String()}, nil
+}
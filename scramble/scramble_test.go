@@ -0,0 +1,62 @@
+package scramble
+
+import (
+	"context"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/cubie"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"testing"
+)
+
+func TestRandomCube(t *testing.T) {
+	g := NewGenerator(1)
+	var parities [2]int
+	var twisted [8]int
+	for i := 0; i < 1000; i++ {
+		c := g.RandomCube()
+		if err := c.Verify(); err != nil {
+			t.Fatal("Failed RandomCube got: ", c, " error: ", err)
+		}
+		parities[cubie.Parity(c.CornerPerm[:])]++
+		for j, x := range c.CornerOrient {
+			if x != 0 {
+				twisted[j]++
+			}
+		}
+	}
+	//both parities and every corner being twisted should be common
+	if parities[0] < 400 || parities[1] < 400 {
+		t.Error("Failed RandomCube parities: ", parities)
+	}
+	for j, x := range twisted {
+		if x < 600 || x > 730 {
+			t.Error("Failed RandomCube corner ", j, " twisted ", x, " times in 1000")
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	a, b := NewGenerator(7), NewGenerator(7)
+	for i := 0; i < 5; i++ {
+		x, err := a.Next(context.Background())
+		if err != nil {
+			t.Fatal("Failed Next got error: ", err)
+		}
+		y, _ := b.Next(context.Background())
+		if x.Moves != y.Moves {
+			t.Error("Failed Next the same seed gave: ", x.Moves, " and ", y.Moves)
+		}
+		c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+		if err := rubikscuberunner.NewOfficialRunner(c).Run(x.Moves); err != nil {
+			t.Error("Failed Next got: ", x.Moves, " error: ", err)
+		}
+		if c.String() != x.Cube.String() {
+			t.Error("Failed Next scramble ", x.Moves, " gave: ", c.String(), " expected: ", x.Cube.String())
+		}
+	}
+	g := NewGenerator(1)
+	g.SetSolver("missing", g.options)
+	if _, err := g.Next(context.Background()); err == nil {
+		t.Error("Failed Next with a missing solver expected an error")
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/davidafox/rubikscubesolver/scramble"
+	"os"
+	"time"
+)
+
+//runScramble prints random state scrambles, it is run with: rubikscubesolver scramble [-n count] [-seed seed]
+func runScramble(args []string) int {
+	flags := flag.NewFlagSet("scramble", flag.ExitOnError)
+	n := flags.Int("n", 1, "the number of scrambles to print")
+	seed := flags.Int64("seed", 0, "seed for the random numbers so the scrambles can be repeated, 0 for a random seed")
+	showState := flags.Bool("state", false, "print the cube state after each scramble")
+	flags.Parse(args)
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	g := scramble.NewGenerator(*seed)
+	for i := 0; i < *n; i++ {
+		s, err := g.Next(context.Background())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return EXIT_ERROR
+		}
+		fmt.Println(s.Moves)
+		if *showState {
+			fmt.Println(s.Cube.String())
+		}
+	}
+	return EXIT_OK
+}
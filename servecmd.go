@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/davidafox/rubikscubesolver/server"
+	"github.com/davidafox/rubikscubesolver/solver"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+//runServe answers solve requests over HTTP, it is run with: rubikscubesolver serve [-addr address]
+func runServe(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	options := newSolverFlags(flags)
+	addr := flags.String("addr", "localhost:8080", "the address to listen on")
+	timeout := flags.Duration("timeout", server.DEFAULT_TIMEOUT, "the longest a solve may take")
+	concurrency := flags.Int("concurrency", 1, "the number of solves run at once")
+	flags.Parse(args)
+	if !solver.Registered(*options.name) {
+		fmt.Fprintln(os.Stderr, solver.ErrUnknownSolver, *options.name)
+		return EXIT_INVALID_INPUT
+	}
+	s := server.New(server.Config{
+		Solver:      *options.name,
+		Options:     options.options(),
+		Timeout:     *timeout,
+		Concurrency: *concurrency,
+	})
+	//the tables are loaded before listening so the first requests don't wait for them
+	log.Println("Loading the", *options.name, "solver")
+	if err := s.Preload(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return EXIT_ERROR
+	}
+	h := &http.Server{
+		Addr:              *addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Println("Listening on", *addr)
+	log.Println(h.ListenAndServe())
+	return EXIT_ERROR
+}
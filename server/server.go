@@ -0,0 +1,303 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/format"
+	"github.com/davidafox/rubikscubesolver/notation"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"github.com/davidafox/rubikscubesolver/solver"
+	"net/http"
+	"strings"
+	"time"
+)
+
+//server answers solve requests over HTTP.
+//POST /solve with {"state": "<54 digits>"} or {"scramble": "R U F'"} and optionally "solver", "timeout_ms" and a "target"
+//state to solve to.
+//The response holds the solution, its number of moves and the time taken, or an error with a code a program can check.
+
+//DEFAULT_TIMEOUT is used when the config has no timeout
+const DEFAULT_TIMEOUT = 30 * time.Second
+
+//MAX_BODY is the largest request body read
+const MAX_BODY = 1 << 20
+
+//error codes
+const (
+	CodeBadRequest       = "bad_request"
+	CodeBadState         = "bad_state"
+	CodeBadScramble      = "bad_scramble"
+	CodeUnknownSolver    = "unknown_solver"
+	CodeBusy             = "busy"
+	CodeTimeout          = "timeout"
+	CodeNoSolution       = "no_solution"
+	CodeInternal         = "internal"
+	CodeDifferentOrbit   = "different_orbit"
+	CodeBadTarget        = "bad_target"
+	CodeWrongLength      = "wrong_length"
+	CodeCenters          = "centers"
+	CodeColorCounts      = "color_counts"
+	CodeCorners          = "corners"
+	CodeEdges            = "edges"
+	CodeTwistedCorner    = "twisted_corner"
+	CodeFlippedEdge      = "flipped_edge"
+	CodeSwappedPieces    = "swapped_pieces"
+	CodeMethodNotAllowed = "method_not_allowed"
+)
+
+//validationCodes holds the code for each error from bytecube
+var validationCodes = map[error]string{
+	bytecube.ErrIncorrectNumber:       CodeWrongLength,
+	format.ErrCenters:                 CodeCenters,
+	bytecube.ErrCenterCubies:          CodeCenters,
+	bytecube.ErrIncorrectColorNumbers: CodeColorCounts,
+	bytecube.ErrIncorrectCorners:      CodeCorners,
+	bytecube.ErrIncorrectSides:        CodeEdges,
+	bytecube.ErrTwistedCorner:         CodeTwistedCorner,
+	bytecube.ErrFlippedSide:           CodeFlippedEdge,
+	bytecube.ErrSwappedCubies:         CodeSwappedPieces,
+}
+
+//Config holds the settings of a server, zero values mean the defaults
+type Config struct {
+	//Solver is the registered solver used when a request doesn't name one, combined by default
+	Solver  string
+	Options solver.Options
+	//Timeout is the longest a solve may take, a request may ask for less
+	Timeout time.Duration
+	//Concurrency is the number of solves run at once, 1 by default since a solve can use every processor
+	Concurrency int
+}
+
+//Request is the body of a solve request, it has a state or a scramble
+type Request struct {
+	State     string `json:"state,omitempty"`
+	Scramble  string `json:"scramble,omitempty"`
+	Solver    string `json:"solver,omitempty"`
+	TimeoutMS int    `json:"timeout_ms,omitempty"`
+	Target    string `json:"target,omitempty"`
+}
+
+//Response is the body of every response, Error is nil when the solve worked
+type Response struct {
+	Solver    string  `json:"solver,omitempty"`
+	State     string  `json:"state,omitempty"`
+	Solution  string  `json:"solution"`
+	Moves     int     `json:"moves"`
+	ElapsedMS float64 `json:"elapsed_ms"`
+	Error     *Error  `json:"error,omitempty"`
+}
+
+//Error describes what went wrong, Code is one of the codes above
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	//Offset is the position in the scramble where it stopped parsing
+	Offset *int `json:"offset,omitempty"`
+}
+
+//Server is an http.Handler that solves cubes
+type Server struct {
+	config Config
+	//slots holds a value for each solve running
+	slots chan struct{}
+	mux   *http.ServeMux
+}
+
+//New returns a server with the config's settings
+func New(config Config) *Server {
+	if config.Solver == "" {
+		config.Solver = "combined"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DEFAULT_TIMEOUT
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	s := new(Server)
+	s.config = config
+	s.slots = make(chan struct{}, config.Concurrency)
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("/solve", s.solve)
+	s.mux.HandleFunc("/solvers", s.solvers)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) solvers(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, solver.Names())
+}
+
+func (s *Server) solve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		fail(w, http.StatusMethodNotAllowed, &Error{Code: CodeMethodNotAllowed, Message: "Use POST"})
+		return
+	}
+	var req Request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MAX_BODY)).Decode(&req); err != nil {
+		fail(w, http.StatusBadRequest, &Error{Code: CodeBadRequest, Message: err.Error()})
+		return
+	}
+	c, e := cube(req)
+	if e != nil {
+		fail(w, http.StatusUnprocessableEntity, e)
+		return
+	}
+	name := req.Solver
+	if name == "" {
+		name = s.config.Solver
+	}
+	timeout := s.config.Timeout
+	if req.TimeoutMS > 0 && time.Duration(req.TimeoutMS)*time.Millisecond < timeout {
+		timeout = time.Duration(req.TimeoutMS) * time.Millisecond
+	}
+	options := s.config.Options
+	reached := c.Solved()
+	if req.Target != "" {
+		target, _, err := format.ToDigits(req.Target)
+		if err != nil {
+			err = &solver.TargetError{Err: err}
+		} else {
+			_, err = solver.Relative(c, target)
+		}
+		if err != nil {
+			fail(w, http.StatusUnprocessableEntity, TargetError(err))
+			return
+		}
+		options.Target = target
+		reached = c.String() == target
+	}
+	resp := &Response{Solver: name, State: c.String()}
+	if reached {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	if !solver.Registered(name) {
+		fail(w, http.StatusBadRequest, &Error{Code: CodeUnknownSolver, Message: solver.ErrUnknownSolver.Error()})
+		return
+	}
+	//the timeout includes waiting for a slot, and a client that goes away stops its solve
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		fail(w, http.StatusServiceUnavailable, &Error{Code: CodeBusy, Message: "Every solver is busy"})
+		return
+	}
+	//building a solver may load or generate its tables, so it is done holding a slot and stops at the timeout
+	start := time.Now()
+	x, err := solver.NewContext(ctx, name, c, options)
+	var cancelErr *solver.CancelError
+	switch {
+	case errors.As(err, &cancelErr):
+		resp.ElapsedMS = float64(time.Since(start)) / float64(time.Millisecond)
+		resp.Error = &Error{Code: CodeTimeout, Message: err.Error()}
+		writeJSON(w, http.StatusGatewayTimeout, resp)
+		return
+	case validationCodes[err] != "":
+		fail(w, http.StatusUnprocessableEntity, StateError(err))
+		return
+	case err != nil:
+		//such as a table that can't be read
+		fail(w, http.StatusInternalServerError, &Error{Code: CodeInternal, Message: err.Error()})
+		return
+	}
+	solution, err := x.SolveContext(ctx)
+	resp.ElapsedMS = float64(time.Since(start)) / float64(time.Millisecond)
+	switch {
+	case errors.As(err, &cancelErr):
+		resp.Error = &Error{Code: CodeTimeout, Message: err.Error()}
+		writeJSON(w, http.StatusGatewayTimeout, resp)
+	case err != nil:
+		resp.Error = &Error{Code: CodeNoSolution, Message: err.Error()}
+		writeJSON(w, http.StatusInternalServerError, resp)
+	default:
+		resp.Solution = solution
+		resp.Moves = len(strings.Fields(solution))
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+//Preload builds the configured solver once so the tables it uses are loaded before the first request
+func (s *Server) Preload() error {
+	c, _ := bytecube.NewCube(bytecube.SOLVED)
+	_, err := solver.New(s.config.Solver, c, s.config.Options)
+	return err
+}
+
+//cube returns the cube given by the state or scramble of a request
+func cube(req Request) (*bytecube.Cube, *Error) {
+	switch {
+	case req.State != "" && req.Scramble != "":
+		return nil, &Error{Code: CodeBadRequest, Message: "Give a state or a scramble, not both"}
+	case req.Scramble != "":
+		seq, err := notation.Parse(req.Scramble)
+		if err != nil {
+			e := &Error{Code: CodeBadScramble, Message: err.Error()}
+			var pe *notation.ParseError
+			if errors.As(err, &pe) {
+				e.Offset = &pe.Offset
+			}
+			return nil, e
+		}
+		c, _ := bytecube.NewCube(bytecube.SOLVED)
+		rubikscuberunner.NewOfficialRunner(c).RunSequence(seq)
+		return c, nil
+	case req.State == "":
+		return nil, &Error{Code: CodeBadRequest, Message: "Give a state or a scramble"}
+	}
+	//a cube with a target is checked together with it by solver.Relative
+	if req.Target != "" {
+		c, _, err := format.Parse(req.State)
+		if err != nil {
+			return nil, StateError(err)
+		}
+		return c, nil
+	}
+	c, err := format.NewValidCube(req.State)
+	if err != nil {
+		return nil, StateError(err)
+	}
+	return c, nil
+}
+
+//StateError returns the error for an error from format.NewValidCube
+func StateError(err error) *Error {
+	code, ok := validationCodes[err]
+	if !ok {
+		code = CodeBadState
+	}
+	return &Error{Code: code, Message: err.Error()}
+}
+
+//TargetError returns the error for an error from solver.Relative
+func TargetError(err error) *Error {
+	var targetErr *solver.TargetError
+	switch {
+	case err == solver.ErrDifferentOrbit:
+		return &Error{Code: CodeDifferentOrbit, Message: err.Error()}
+	case errors.As(err, &targetErr):
+		return &Error{Code: CodeBadTarget, Message: err.Error()}
+	}
+	return StateError(err)
+}
+
+func fail(w http.ResponseWriter, status int, e *Error) {
+	writeJSON(w, status, &Response{Error: e})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
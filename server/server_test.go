@@ -0,0 +1,214 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/cubie"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"github.com/davidafox/rubikscubesolver/solver"
+	_ "github.com/davidafox/rubikscubesolver/twophase"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+//slowSolver doesn't finish until its context is done
+type slowSolver struct {
+	started chan bool
+}
+
+func (s *slowSolver) SolveContext(ctx context.Context) (string, error) {
+	if s.started != nil {
+		s.started <- true
+	}
+	<-ctx.Done()
+	return "", &solver.CancelError{Err: ctx.Err()}
+}
+
+func (s *slowSolver) SetObserver(o solver.Observer) {}
+
+var slowStarted = make(chan bool, 10)
+
+//building is sent to when the building solver starts being built, which finishes when release is sent to or its
+//context is done
+var building = make(chan bool, 10)
+var release = make(chan bool)
+
+func init() {
+	solver.Register("slow", func(ctx context.Context, c *bytecube.Cube, o solver.Options) (solver.Solver, error) {
+		return &slowSolver{slowStarted}, nil
+	})
+	solver.Register("building", func(ctx context.Context, c *bytecube.Cube, o solver.Options) (solver.Solver, error) {
+		building <- true
+		select {
+		case <-release:
+			return &slowSolver{}, nil
+		case <-ctx.Done():
+			return nil, &solver.CancelError{Err: ctx.Err()}
+		}
+	})
+	solver.Register("broken", func(ctx context.Context, c *bytecube.Cube, o solver.Options) (solver.Solver, error) {
+		return nil, errors.New("The table could not be read")
+	})
+}
+
+func post(t *testing.T, h http.Handler, body string) (int, *Response) {
+	req := httptest.NewRequest(http.MethodPost, "/solve", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	resp := new(Response)
+	if err := json.Unmarshal(rec.Body.Bytes(), resp); err != nil {
+		t.Fatal("Failed to decode the response: ", rec.Body.String())
+	}
+	return rec.Code, resp
+}
+
+func TestSolve(t *testing.T) {
+	s := New(Config{Solver: "twophase"})
+	code, resp := post(t, s, `{"scramble": "R U R' U' F2 D"}`)
+	if code != http.StatusOK || resp.Error != nil {
+		t.Fatal("Failed solve got: ", code, resp.Error)
+	}
+	c, _ := bytecube.NewCube(resp.State)
+	rubikscuberunner.NewOfficialRunner(c).Run(resp.Solution)
+	if !c.Solved() || resp.Moves != len(strings.Fields(resp.Solution)) || resp.Solver != "twophase" {
+		t.Error("Failed solve got: ", resp)
+	}
+	code, resp = post(t, s, `{"state": "UUFUUFUUFRRRRRRRRRFFDFFDFFDDDBDDBDDBLLLLLLLLLUBBUBBUBB"}`)
+	c, _ = bytecube.NewCube(resp.State)
+	rubikscuberunner.NewOfficialRunner(c).Run(resp.Solution)
+	if code != http.StatusOK || !c.Solved() {
+		t.Error("Failed solve of a Kociemba state got: ", code, resp.Solution, resp.Error)
+	}
+	code, resp = post(t, s, `{"state": "`+bytecube.SOLVED+`"}`)
+	if code != http.StatusOK || resp.Solution != "" || resp.Moves != 0 {
+		t.Error("Failed solve of a solved cube got: ", code, resp)
+	}
+	target, _ := bytecube.NewCube(bytecube.SOLVED)
+	rubikscuberunner.NewOfficialRunner(target).Run("F R U2 B'")
+	code, resp = post(t, s, `{"scramble": "L D", "target": "`+target.String()+`"}`)
+	c, _ = bytecube.NewCube(resp.State)
+	rubikscuberunner.NewOfficialRunner(c).Run(resp.Solution)
+	if code != http.StatusOK || c.String() != target.String() {
+		t.Error("Failed solve to a target got: ", code, resp.Solution, resp.Error)
+	}
+	//a cube that can't be solved reaches a target that can't either
+	flipped, _ := bytecube.NewCube(bytecube.SOLVED[:1] + "4" + bytecube.SOLVED[2:43] + "0" + bytecube.SOLVED[44:])
+	target, _ = bytecube.NewCube(flipped.String())
+	rubikscuberunner.NewOfficialRunner(target).Run("R U")
+	code, resp = post(t, s, `{"state": "`+flipped.String()+`", "target": "`+target.String()+`"}`)
+	rubikscuberunner.NewOfficialRunner(flipped).Run(resp.Solution)
+	if code != http.StatusOK || flipped.String() != target.String() {
+		t.Error("Failed solve of a cube that can't be solved to its target got: ", code, resp.Solution, resp.Error)
+	}
+}
+
+func TestSolveErrors(t *testing.T) {
+	s := New(Config{Solver: "twophase"})
+	tooManyOnes := "100000000111111111222222222333333333444444444555555555"
+	c := cubie.NewCube()
+	c.CornerOrient[0], c.CornerOrient[1] = 1, 1
+	twisted := c.Bytecube().String()
+	data := []struct {
+		body   string
+		status int
+		code   string
+	}{
+		{`{"state": "123"}`, http.StatusUnprocessableEntity, CodeWrongLength},
+		{`{"state": "` + strings.Replace(bytecube.SOLVED, "0", "7", 1) + `"}`, http.StatusUnprocessableEntity, CodeBadState},
+		{`{"state": "` + bytecube.SOLVED[:40] + "0" + bytecube.SOLVED[41:] + `"}`, http.StatusUnprocessableEntity, CodeCenters},
+		{`{"state": "` + tooManyOnes + `"}`, http.StatusUnprocessableEntity, CodeColorCounts},
+		{`{"state": "` + twisted + `"}`, http.StatusUnprocessableEntity, CodeTwistedCorner},
+		{`{"scramble": "R U Q"}`, http.StatusUnprocessableEntity, CodeBadScramble},
+		{`{"scramble": "R", "state": "` + bytecube.SOLVED + `"}`, http.StatusUnprocessableEntity, CodeBadRequest},
+		{`{}`, http.StatusUnprocessableEntity, CodeBadRequest},
+		{`{"state": `, http.StatusBadRequest, CodeBadRequest},
+		{`{"scramble": "R", "solver": "missing"}`, http.StatusBadRequest, CodeUnknownSolver},
+		{`{"scramble": "R", "target": "123"}`, http.StatusUnprocessableEntity, CodeBadTarget},
+		{`{"scramble": "R", "target": "` + tooManyOnes + `"}`, http.StatusUnprocessableEntity, CodeBadTarget},
+		{`{"scramble": "R", "target": "` + twisted + `"}`, http.StatusUnprocessableEntity, CodeDifferentOrbit},
+	}
+	for _, x := range data {
+		code, resp := post(t, s, x.body)
+		if code != x.status || resp.Error == nil || resp.Error.Code != x.code {
+			t.Error("Failed solve for ", x.body, " got: ", code, resp.Error, " expected: ", x.status, x.code)
+		}
+	}
+	_, resp := post(t, s, `{"scramble": "R U Q"}`)
+	if resp.Error.Offset == nil || *resp.Error.Offset != 4 {
+		t.Error("Failed solve did not give the offset of the scramble error got: ", resp.Error)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/solve", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Error("Failed GET /solve got: ", rec.Code)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	s := New(Config{Solver: "slow", Timeout: time.Minute, Concurrency: 1})
+	start := time.Now()
+	done := make(chan int)
+	go func() {
+		code, resp := post(t, s, `{"scramble": "R", "timeout_ms": 200}`)
+		if resp.Error == nil || resp.Error.Code != CodeTimeout {
+			t.Error("Failed timeout got: ", code, resp.Error)
+		}
+		done <- code
+	}()
+	<-slowStarted
+	//the only slot is taken so a second request waits and gives up
+	code, resp := post(t, s, `{"scramble": "U", "timeout_ms": 50}`)
+	if code != http.StatusServiceUnavailable || resp.Error.Code != CodeBusy {
+		t.Error("Failed concurrency limit got: ", code, resp.Error)
+	}
+	if code := <-done; code != http.StatusGatewayTimeout {
+		t.Error("Failed timeout got: ", code)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Error("Failed timeout took ", elapsed)
+	}
+}
+
+func TestBuildInSlot(t *testing.T) {
+	s := New(Config{Solver: "building", Concurrency: 1})
+	done := make(chan int)
+	go func() {
+		code, resp := post(t, s, `{"scramble": "R", "timeout_ms": 200}`)
+		if code != http.StatusGatewayTimeout || resp.Error == nil || resp.Error.Code != CodeTimeout {
+			t.Error("Failed timeout while building got: ", code, resp.Error)
+		}
+		done <- 0
+	}()
+	<-building
+	//the solver being built holds the only slot so a second request isn't built
+	code, resp := post(t, s, `{"scramble": "U", "timeout_ms": 50}`)
+	if code != http.StatusServiceUnavailable || resp.Error.Code != CodeBusy {
+		t.Error("Failed concurrency limit while building got: ", code, resp.Error)
+	}
+	select {
+	case <-building:
+		t.Error("Failed a solver was built without a slot")
+	default:
+	}
+	//the build stops at the timeout without being released
+	<-done
+	go func() { release <- true }()
+	if err := s.Preload(); err != nil {
+		t.Error("Failed Preload got: ", err)
+	}
+	select {
+	case <-building:
+	default:
+		t.Error("Failed Preload did not build the solver")
+	}
+	//a solver that can't be built for a reason other than the cube is the server's error
+	code, resp = post(t, s, `{"scramble": "R", "solver": "broken"}`)
+	if code != http.StatusInternalServerError || resp.Error == nil || resp.Error.Code != CodeInternal {
+		t.Error("Failed build error got: ", code, resp.Error)
+	}
+}
@@ -0,0 +1,210 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"github.com/davidafox/rubikscubesolver/algorithm"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/cubie"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var ErrUnknownSolver = errors.New("There is no solver with that name")
+var ErrDifferentOrbit = errors.New("The target can't be reached from the cube by turning faces")
+
+//Solver is the shape shared by the solver packages once they are created through the registry.
+//Solutions are in official notation.
+type Solver interface {
+	SolveContext(ctx context.Context) (string, error)
+	SetObserver(o Observer)
+}
+
+//Options holds the settings of every solver, a solver ignores the ones it doesn't use and zero values mean its default.
+type Options struct {
+	//Depth is the breadth-first depth of the combined solver
+	Depth int
+	//MemoryBudget is the memory in bytes the combined solver may use for its breadth-first states
+	MemoryBudget int
+	//MaxLength is the longest solution twophase looks for
+	MaxLength int
+	//TableDir is where optimal loads its pattern databases from and saves them to once generated
+	TableDir string
+	//Target is the 54 digit state solutions lead to instead of the solved cube, it is passed to the NewSolver of each
+	//solver package
+	Target string
+}
+
+//Constructor creates a solver for c which must be a valid cube. A solver that takes long to create, such as one that
+//generates tables, stops and returns a *CancelError when ctx is done.
+type Constructor func(ctx context.Context, c *bytecube.Cube, o Options) (Solver, error)
+
+var registry = struct {
+	sync.RWMutex
+	constructors map[string]Constructor
+}{constructors: make(map[string]Constructor)}
+
+//Register makes a solver available by name, it is called from the init function of the solver packages
+func Register(name string, c Constructor) {
+	registry.Lock()
+	defer registry.Unlock()
+	if c == nil {
+		panic("solver: Register constructor is nil for " + name)
+	}
+	if _, ok := registry.constructors[name]; ok {
+		panic("solver: Register called twice for " + name)
+	}
+	registry.constructors[name] = c
+}
+
+//New creates the solver registered as name for c
+func New(name string, c *bytecube.Cube, o Options) (Solver, error) {
+	return NewContext(context.Background(), name, c, o)
+}
+
+//NewContext is New that stops creating the solver and returns a *CancelError when ctx is canceled or its deadline
+//passes, so a deadline covers the tables a solver loads or generates as well as its solve
+func NewContext(ctx context.Context, name string, c *bytecube.Cube, o Options) (Solver, error) {
+	registry.RLock()
+	constructor, ok := registry.constructors[name]
+	registry.RUnlock()
+	if !ok {
+		return nil, ErrUnknownSolver
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, &CancelError{Err: err}
+	}
+	return constructor(ctx, c, o)
+}
+
+//Relative returns the cube that a sequence of face turns solves exactly when it takes c to target, so any solver finds
+//the way from c to target by solving it. A target whose stickers aren't the pieces of a cube gives a *TargetError and
+//a cube whose stickers aren't gives its validation error. It returns ErrDifferentOrbit when target has other centers
+//or can't be reached from c, such as a target with one corner twisted from a cube that can be solved.
+func Relative(c *bytecube.Cube, target string) (*bytecube.Cube, error) {
+	if len(target) != 54 {
+		return nil, &TargetError{bytecube.ErrIncorrectNumber}
+	}
+	for i := 0; i < len(target); i++ {
+		if target[i] < '0' || target[i] > '5' {
+			return nil, &TargetError{bytecube.ErrInvalidColor}
+		}
+	}
+	t, _ := bytecube.NewCube(target)
+	if err := pieceError(t); err != nil {
+		return nil, &TargetError{err}
+	}
+	if err := pieceError(c); err != nil {
+		return nil, err
+	}
+	for side := 0; side < 6; side++ {
+		if c.String()[side*9+4] != target[side*9+4] {
+			return nil, ErrDifferentOrbit
+		}
+	}
+	start, err := cubie.FromBytecube(c)
+	if err != nil {
+		return nil, err
+	}
+	goal, err := cubie.FromBytecube(t)
+	if err != nil {
+		return nil, &TargetError{err}
+	}
+	//c followed by the solution is target, so target undone followed by c and the solution is solved
+	relative := goal.Inverse()
+	relative.Multiply(start)
+	if relative.Verify() != nil {
+		return nil, ErrDifferentOrbit
+	}
+	return relative.Bytecube(), nil
+}
+
+//RelativeState is Relative for the solver packages that start from a state, an empty target leaves the state as it is
+func RelativeState(state, target string) (string, error) {
+	if target == "" {
+		return state, nil
+	}
+	c, err := bytecube.NewCube(state)
+	if err != nil {
+		return "", err
+	}
+	relative, err := Relative(c, target)
+	if err != nil {
+		return "", err
+	}
+	return relative.String(), nil
+}
+
+//pieceError returns the validation error of a cube whose stickers aren't the pieces of a cube, a cube that only has
+//pieces twisted, flipped or swapped gives nil
+func pieceError(c *bytecube.Cube) error {
+	_, err := c.Validate()
+	switch err {
+	case bytecube.ErrTwistedCorner, bytecube.ErrFlippedSide, bytecube.ErrSwappedCubies:
+		return nil
+	}
+	return err
+}
+
+//Names returns the registered solvers in alphabetical order
+func Names() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	names := make([]string, 0, len(registry.constructors))
+	for name := range registry.constructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//Registered is true when a solver is registered as name
+func Registered(name string) bool {
+	registry.RLock()
+	defer registry.RUnlock()
+	_, ok := registry.constructors[name]
+	return ok
+}
+
+//legacy converts the solutions of a solver using the layer count notation of rubikscuberunner.Runner
+type legacy struct {
+	s Solver
+}
+
+//Legacy wraps a 3x3 solver whose solutions are in the legacy layer count notation so its solutions are official face turns
+func Legacy(s Solver) Solver {
+	return &legacy{s}
+}
+
+func (l *legacy) SolveContext(ctx context.Context) (string, error) {
+	solution, err := l.s.SolveContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	return legacyFaceTurns(solution)
+}
+
+//legacyFaceTurns converts a legacy solution to simplified face turns
+func legacyFaceTurns(solution string) (string, error) {
+	official, err := rubikscuberunner.FaceTurns(solution)
+	if err != nil {
+		return "", err
+	}
+	return algorithm.SimplifyString(official)
+}
+
+func (l *legacy) SetObserver(o Observer) {
+	if o == nil {
+		l.s.SetObserver(nil)
+		return
+	}
+	l.s.SetObserver(func(e Event) {
+		if e.Kind == SolutionFound {
+			e.Solution, _ = legacyFaceTurns(e.Solution)
+			e.Depth = len(strings.Fields(e.Solution))
+		}
+		o(e)
+	})
+}
@@ -0,0 +1,123 @@
+package solver
+
+import (
+	"context"
+	"strconv"
+)
+
+//solver holds what the solver packages share.
+
+//CancelError is returned when a solve is stopped by its context being canceled or passing its deadline.
+//Err is the context's error, context.Canceled or context.DeadlineExceeded.
+type CancelError struct {
+	Err error
+}
+
+func (e *CancelError) Error() string {
+	return "The solve was stopped: " + e.Err.Error()
+}
+
+func (e *CancelError) Unwrap() error {
+	return e.Err
+}
+
+//Stopper tells a search when to stop because its context is done, Err then gives the *CancelError to return
+type Stopper struct {
+	ctx   context.Context
+	nodes int
+	err   error
+}
+
+func NewStopper(ctx context.Context) *Stopper {
+	return &Stopper{ctx: ctx}
+}
+
+//Stopped checks the context on the first call and then every 1024 calls since checking it is slow compared to a move,
+//it is for the inner loop of a search
+func (s *Stopper) Stopped() bool {
+	s.nodes++
+	if s.err == nil && s.nodes%1024 == 1 {
+		s.err = s.ctx.Err()
+	}
+	return s.err != nil
+}
+
+//Done checks the context every call, it is for places reached once in many moves such as between breadth-first layers
+func (s *Stopper) Done() bool {
+	if s.err == nil {
+		s.err = s.ctx.Err()
+	}
+	return s.err != nil
+}
+
+//Err returns nil until Stopped or Done is true, then a *CancelError holding the context's error
+func (s *Stopper) Err() error {
+	if s.err == nil {
+		return nil
+	}
+	return &CancelError{Err: s.err}
+}
+
+//TargetError is returned when the target to solve to isn't a cube, Err is the validation error such as
+//bytecube.ErrIncorrectCorners. A target that has its pieces but can't be solved isn't an error.
+type TargetError struct {
+	Err error
+}
+
+func (e *TargetError) Error() string {
+	return "The target is not a cube: " + e.Err.Error()
+}
+
+func (e *TargetError) Unwrap() error {
+	return e.Err
+}
+
+type EventKind int
+
+const (
+	//LayerDone is sent when a breadth-first layer has been expanded
+	LayerDone EventKind = iota
+	//DepthStarted is sent when an iterative deepening search starts a new depth
+	DepthStarted
+	//SolutionFound is sent when a solution has been found
+	SolutionFound
+	//ExpansionStopped is sent when a breadth-first side stops expanding because of a memory budget
+	ExpansionStopped
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case LayerDone:
+		return "LayerDone"
+	case DepthStarted:
+		return "DepthStarted"
+	case SolutionFound:
+		return "SolutionFound"
+	case ExpansionStopped:
+		return "ExpansionStopped"
+	}
+	return "EventKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+//Event describes the progress of a solve.
+//Depth is the layer for LayerDone, the depth being searched for DepthStarted, the number of moves for SolutionFound
+//and the number of layers the side reached for ExpansionStopped.
+//Side is the side whose layer was expanded or stopped, 0 for the starting state and 1 for the solved state.
+//Frontier holds the number of states on the frontier of each side, solvers searching from one side only use Frontier[0].
+type Event struct {
+	Kind     EventKind
+	Depth    int
+	Side     int
+	Frontier [2]int
+	Solution string
+}
+
+//Observer receives the events of a solve. It is called from the goroutine running the solve.
+type Observer func(Event)
+
+//Notify sends e to o, a nil Observer ignores every event
+func (o Observer) Notify(e Event) {
+	if o != nil {
+		o(e)
+	}
+}
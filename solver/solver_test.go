@@ -0,0 +1,198 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"reflect"
+	"testing"
+)
+
+func TestCancelError(t *testing.T) {
+	var err error = &CancelError{context.DeadlineExceeded}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("Failed CancelError does not unwrap to ", context.DeadlineExceeded)
+	}
+	var cancelErr *CancelError
+	if !errors.As(err, &cancelErr) {
+		t.Error("Failed CancelError is not a *CancelError")
+	}
+	if err.Error() != "The solve was stopped: context deadline exceeded" {
+		t.Error("Failed Error got: ", err.Error())
+	}
+}
+
+func TestStopper(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewStopper(ctx)
+	if s.Stopped() || s.Done() || s.Err() != nil {
+		t.Error("Failed Stopper stopped before its context was done")
+	}
+	cancel()
+	//Stopped only checks the context every 1024 calls, the first was before it was canceled
+	n := 2
+	for ; !s.Stopped(); n++ {
+	}
+	var cancelErr *CancelError
+	if n != 1025 || !errors.As(s.Err(), &cancelErr) || !errors.Is(s.Err(), context.Canceled) {
+		t.Error("Failed Stopped after ", n, " calls got: ", s.Err())
+	}
+	if s = NewStopper(ctx); !s.Done() || !errors.Is(s.Err(), context.Canceled) {
+		t.Error("Failed Done got: ", s.Err())
+	}
+}
+
+func TestObserver(t *testing.T) {
+	var o Observer
+	o.Notify(Event{Kind: LayerDone})
+	var got []Event
+	o = func(e Event) {
+		got = append(got, e)
+	}
+	o.Notify(Event{Kind: DepthStarted, Depth: 3})
+	if len(got) != 1 || got[0].Kind != DepthStarted || got[0].Depth != 3 {
+		t.Error("Failed Notify got: ", got)
+	}
+	if DepthStarted.String() != "DepthStarted" {
+		t.Error("Failed String got: ", DepthStarted.String())
+	}
+}
+
+type fakeSolver struct {
+	solution string
+	observer Observer
+}
+
+func (f *fakeSolver) SolveContext(ctx context.Context) (string, error) {
+	f.observer.Notify(Event{Kind: SolutionFound, Depth: len(f.solution) / 2, Solution: f.solution})
+	return f.solution, nil
+}
+
+func (f *fakeSolver) SetObserver(o Observer) {
+	f.observer = o
+}
+
+func TestRegistry(t *testing.T) {
+	Register("fake", func(ctx context.Context, c *bytecube.Cube, o Options) (Solver, error) {
+		if o.Target != "" {
+			relative, err := Relative(c, o.Target)
+			if err != nil {
+				return nil, err
+			}
+			c = relative
+		}
+		return &fakeSolver{solution: c.String()[:o.Depth]}, nil
+	})
+	Register("fakelegacy", func(ctx context.Context, c *bytecube.Cube, o Options) (Solver, error) {
+		return Legacy(&fakeSolver{solution: "R0C1T0"}), nil
+	})
+	defer func() {
+		delete(registry.constructors, "fake")
+		delete(registry.constructors, "fakelegacy")
+	}()
+	c, _ := bytecube.NewCube("000000000111111111222222222333333333444444444555555555")
+	if _, err := New("missing", c, Options{}); err != ErrUnknownSolver {
+		t.Error("Failed New got: ", err, " expected: ", ErrUnknownSolver)
+	}
+	s, err := New("fake", c, Options{Depth: 3})
+	if err != nil {
+		t.Fatal("Failed New got error: ", err)
+	}
+	if result, _ := s.SolveContext(context.Background()); result != "000" {
+		t.Error("Failed New did not pass the options got: ", result)
+	}
+	//the target is passed on, the fake solver is given the cube that its solution turns into the solved cube
+	target, _ := bytecube.NewCube(c.String())
+	rubikscuberunner.NewOfficialRunner(target).Run("R")
+	relative, _ := bytecube.NewCube(c.String())
+	rubikscuberunner.NewOfficialRunner(relative).Run("R'")
+	s, _ = New("fake", c, Options{Depth: 54, Target: target.String()})
+	if result, _ := s.SolveContext(context.Background()); result != relative.String() {
+		t.Error("Failed New with a target got: ", result, " expected: ", relative.String())
+	}
+	//a context that is already done stops the solver being created
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var cancelErr *CancelError
+	if _, err := NewContext(ctx, "fake", c, Options{}); !errors.As(err, &cancelErr) || !errors.Is(err, context.Canceled) {
+		t.Error("Failed NewContext got: ", err, " expected: ", context.Canceled)
+	}
+	s, _ = New("fakelegacy", c, Options{})
+	var found Event
+	s.SetObserver(func(e Event) {
+		found = e
+	})
+	result, err := s.SolveContext(context.Background())
+	if result != "U F' B" || err != nil || found.Solution != result || found.Depth != 3 {
+		t.Error("Failed Legacy got: ", result, err, found)
+	}
+	names := Names()
+	if !reflect.DeepEqual(names, []string{"fake", "fakelegacy"}) {
+		t.Error("Failed Names got: ", names)
+	}
+}
+
+func TestRelative(t *testing.T) {
+	const solved = "000000000111111111222222222333333333444444444555555555"
+	//the UF edge flipped
+	flipped := []byte(solved)
+	flipped[0*9+1], flipped[4*9+7] = '4', '0'
+	//the UFR corner with two U stickers
+	badCorner := []byte(solved)
+	badCorner[3*9+0] = '4'
+	badCorner[4*9+1] = '3'
+	tests := []struct {
+		target string
+		err    error
+		//bad is true when the target isn't a cube
+		bad bool
+	}{
+		{solved[:53], bytecube.ErrIncorrectNumber, true},
+		{solved[:53] + "6", bytecube.ErrInvalidColor, true},
+		{solved[:53] + "4", bytecube.ErrIncorrectColorNumbers, true},
+		{"000010000111111110222222222333333333444444444555555555", bytecube.ErrCenterCubies, true},
+		{string(badCorner), bytecube.ErrIncorrectCorners, true},
+		//the front and back centers swapped
+		{"000020000111111111222202222333333333444444444555555555", ErrDifferentOrbit, false},
+		{string(flipped), ErrDifferentOrbit, false},
+	}
+	c, _ := bytecube.NewCube(solved)
+	for _, x := range tests {
+		_, err := Relative(c, x.target)
+		var targetErr *TargetError
+		if !errors.Is(err, x.err) || errors.As(err, &targetErr) != x.bad {
+			t.Error("Failed Relative to ", x.target, " got: ", err, " expected: ", x.err)
+		}
+	}
+	//a cube that isn't one gives its own error
+	bad, _ := bytecube.NewCube(string(badCorner))
+	if _, err := Relative(bad, solved); err != bytecube.ErrIncorrectCorners {
+		t.Error("Failed Relative from a cube that isn't one got: ", err)
+	}
+	//the sequence from a cube to a target solves the relative cube
+	for _, x := range []struct{ start, moves string }{
+		{"", "R U F'"},
+		{"R U", "F2 D' L B"},
+		{"L' B2 D", "U R2 F L' D2"},
+	} {
+		start, _ := bytecube.NewCube(solved)
+		rubikscuberunner.NewOfficialRunner(start).Run(x.start)
+		target, _ := bytecube.NewCube(start.String())
+		rubikscuberunner.NewOfficialRunner(target).Run(x.moves)
+		relative, err := Relative(start, target.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		rubikscuberunner.NewOfficialRunner(relative).Run(x.moves)
+		if !relative.Solved() {
+			t.Error("Failed Relative from ", x.start, " by ", x.moves, " got: ", relative.String())
+		}
+	}
+	//a target that can't be solved is reached from a cube that can't either
+	start, _ := bytecube.NewCube(string(flipped))
+	rubikscuberunner.NewOfficialRunner(start).Run("R U")
+	if _, err := Relative(start, string(flipped)); err != nil {
+		t.Error("Failed Relative between flipped cubes got: ", err)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/format"
+	"github.com/davidafox/rubikscubesolver/notation"
+	"github.com/davidafox/rubikscubesolver/render"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"os"
+	"strings"
+)
+
+//runSVG writes an image of a cube, it is run with: rubikscubesolver svg [-state state | -scramble moves] [-steps moves] [-view net|isometric]
+func runSVG(args []string) int {
+	flags := flag.NewFlagSet("svg", flag.ExitOnError)
+	state := flags.String("state", bytecube.SOLVED, "the state to draw")
+	scrambleMoves := flags.String("scramble", "", "moves applied to a solved cube to give the state to draw instead of -state")
+	steps := flags.String("steps", "", "moves to draw a step of each, such as an algorithm from the state")
+	view := flags.String("view", "net", "how the cube is drawn: net or isometric")
+	palette := flags.String("colors", "", "the colors 0 to 5 separated by commas, such as white,#ff0000")
+	mask := flags.String("mask", "", "stickers to draw gray: ll for all but the last layer or 54 characters of x and .")
+	size := flags.Int("size", render.STICKER_SIZE, "the width of a sticker in pixels")
+	out := flags.String("out", "-", "the file to write the image to, - for stdout")
+	flags.Parse(args)
+	o := render.SVGOptions{Size: *size}
+	switch *view {
+	case "net":
+		o.View = render.Net
+	case "isometric":
+		o.View = render.Isometric
+	default:
+		fmt.Fprintln(os.Stderr, "Unknown view:", *view)
+		return EXIT_INVALID_INPUT
+	}
+	var err error
+	if *palette != "" {
+		if o.Palette, err = render.ParsePalette(*palette); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return EXIT_INVALID_INPUT
+		}
+	}
+	switch *mask {
+	case "":
+	case "ll":
+		o.Mask = render.LAST_LAYER
+	default:
+		if o.Mask, err = render.ParseMask(*mask); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return EXIT_INVALID_INPUT
+		}
+	}
+	var c *bytecube.Cube
+	if *scrambleMoves != "" {
+		seq, err := notation.Parse(*scrambleMoves)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return EXIT_INVALID_INPUT
+		}
+		c, _ = bytecube.NewCube(bytecube.SOLVED)
+		rubikscuberunner.NewOfficialRunner(c).RunSequence(seq)
+	} else if c, err = format.NewValidCube(strings.TrimSpace(*state)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return stateExitCode(err)
+	}
+	image := render.SVG(c, o)
+	if *steps != "" {
+		seq, err := notation.Parse(*steps)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return EXIT_INVALID_INPUT
+		}
+		image = render.SVGSteps(c, seq, o)
+	}
+	w := os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return EXIT_ERROR
+		}
+		defer f.Close()
+		w = f
+	}
+	if _, err := w.WriteString(image); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return EXIT_ERROR
+	}
+	return EXIT_OK
+}
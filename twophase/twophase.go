@@ -0,0 +1,405 @@
+package twophase
+
+import (
+	"context"
+	"errors"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/cubie"
+	"github.com/davidafox/rubikscubesolver/solver"
+	"strings"
+	"sync"
+)
+
+//twophase solves a cube using Kociemba's two-phase algorithm.
+//Phase 1 moves the cube into the subgroup <U,D,R2,L2,F2,B2> (all corners and
+//edges oriented and the four middle layer edges in the middle layer) and
+//phase 2 solves the cube using only moves from that subgroup.
+
+var ErrNoSolution = errors.New("No solution was found within the maximum length")
+
+const N_MOVE = cubie.N_MOVE
+const N_TWIST = 2187
+const N_FLIP = 2048
+const N_SLICE = 495
+const N_CORNER_PERM = 40320
+const N_EDGE_PERM = 40320
+const N_SLICE_PERM = 24
+
+//the moves that keep the cube in the phase 2 subgroup
+var phase2Moves = []int{0, 1, 2, 4, 7, 9, 10, 11, 13, 16}
+
+var twistMove [N_TWIST][N_MOVE]int
+var flipMove [N_FLIP][N_MOVE]int
+var sliceMove [N_SLICE][N_MOVE]int
+var cornerPermMove [N_CORNER_PERM][N_MOVE]int
+var edgePermMove [N_EDGE_PERM][N_MOVE]int
+var slicePermMove [N_SLICE_PERM][N_MOVE]int
+
+var sliceTwistPrune []int8
+var sliceFlipPrune []int8
+var cornerSlicePrune []int8
+var edgeSlicePrune []int8
+
+var tablesOnce sync.Once
+
+//udSlice is the positions of the FR, FL, BL and BR edges ignoring their order
+func udSlice(c *cubie.Cube) int {
+	a, x := 0, 0
+	for j := cubie.BR; j >= cubie.UR; j-- {
+		if c.EdgePerm[j] >= cubie.FR {
+			a += binomial(11-j, x+1)
+			x++
+		}
+	}
+	return a
+}
+
+func setUDSlice(c *cubie.Cube, idx int) {
+	slice := []int{cubie.FR, cubie.FL, cubie.BL, cubie.BR}
+	other := []int{cubie.UR, cubie.UF, cubie.UL, cubie.UB, cubie.DR, cubie.DF, cubie.DL, cubie.DB}
+	for j := range c.EdgePerm {
+		c.EdgePerm[j] = -1
+	}
+	x := 4
+	for j := cubie.UR; j <= cubie.BR; j++ {
+		if idx-binomial(11-j, x) >= 0 {
+			c.EdgePerm[j] = slice[4-x]
+			idx -= binomial(11-j, x)
+			x--
+		}
+	}
+	x = 0
+	for j := cubie.UR; j <= cubie.BR; j++ {
+		if c.EdgePerm[j] == -1 {
+			c.EdgePerm[j] = other[x]
+			x++
+		}
+	}
+}
+
+func binomial(n, k int) int {
+	if n < k {
+		return 0
+	}
+	if k > n/2 {
+		k = n - k
+	}
+	r := 1
+	for i := 1; i <= k; i++ {
+		r = r * (n - k + i) / i
+	}
+	return r
+}
+
+func cornerPerm(c *cubie.Cube) int {
+	return cubie.PermIndex(c.CornerPerm[:])
+}
+
+func setCornerPerm(c *cubie.Cube, idx int) {
+	cubie.SetPermIndex(c.CornerPerm[:], idx, 0)
+}
+
+func edgePerm(c *cubie.Cube) int {
+	return cubie.PermIndex(c.EdgePerm[:8])
+}
+
+func setEdgePerm(c *cubie.Cube, idx int) {
+	cubie.SetPermIndex(c.EdgePerm[:8], idx, 0)
+}
+
+func slicePerm(c *cubie.Cube) int {
+	return cubie.PermIndex(c.EdgePerm[8:])
+}
+
+func setSlicePerm(c *cubie.Cube, idx int) {
+	cubie.SetPermIndex(c.EdgePerm[8:], idx, cubie.FR)
+}
+
+func initTables() {
+	for i := 0; i < N_TWIST; i++ {
+		c := cubie.NewCube()
+		c.SetTwist(i)
+		for m := 0; m < N_MOVE; m++ {
+			d := *c
+			d.Move(m)
+			twistMove[i][m] = d.Twist()
+		}
+	}
+	for i := 0; i < N_FLIP; i++ {
+		c := cubie.NewCube()
+		c.SetFlip(i)
+		for m := 0; m < N_MOVE; m++ {
+			d := *c
+			d.Move(m)
+			flipMove[i][m] = d.Flip()
+		}
+	}
+	for i := 0; i < N_SLICE; i++ {
+		c := cubie.NewCube()
+		setUDSlice(c, i)
+		for m := 0; m < N_MOVE; m++ {
+			d := *c
+			d.Move(m)
+			sliceMove[i][m] = udSlice(&d)
+		}
+	}
+	for i := 0; i < N_CORNER_PERM; i++ {
+		c := cubie.NewCube()
+		setCornerPerm(c, i)
+		for m := 0; m < N_MOVE; m++ {
+			d := *c
+			d.Move(m)
+			cornerPermMove[i][m] = cornerPerm(&d)
+		}
+	}
+	for i := 0; i < N_EDGE_PERM; i++ {
+		c := cubie.NewCube()
+		setEdgePerm(c, i)
+		for _, m := range phase2Moves {
+			d := *c
+			d.Move(m)
+			edgePermMove[i][m] = edgePerm(&d)
+		}
+	}
+	for i := 0; i < N_SLICE_PERM; i++ {
+		c := cubie.NewCube()
+		setSlicePerm(c, i)
+		for _, m := range phase2Moves {
+			d := *c
+			d.Move(m)
+			slicePermMove[i][m] = slicePerm(&d)
+		}
+	}
+	all := make([]int, N_MOVE)
+	for i := range all {
+		all[i] = i
+	}
+	sliceTwistPrune = buildPruneTable(N_SLICE, N_TWIST, func(a, m int) int { return sliceMove[a][m] }, func(b, m int) int { return twistMove[b][m] }, all)
+	sliceFlipPrune = buildPruneTable(N_SLICE, N_FLIP, func(a, m int) int { return sliceMove[a][m] }, func(b, m int) int { return flipMove[b][m] }, all)
+	cornerSlicePrune = buildPruneTable(N_SLICE_PERM, N_CORNER_PERM, func(a, m int) int { return slicePermMove[a][m] }, func(b, m int) int { return cornerPermMove[b][m] }, phase2Moves)
+	edgeSlicePrune = buildPruneTable(N_SLICE_PERM, N_EDGE_PERM, func(a, m int) int { return slicePermMove[a][m] }, func(b, m int) int { return edgePermMove[b][m] }, phase2Moves)
+}
+
+//buildPruneTable does a breadth first search from the solved coordinates 0,0 and records the number of moves to reach each pair
+func buildPruneTable(n1, n2 int, move1, move2 func(int, int) int, moves []int) []int8 {
+	table := make([]int8, n1*n2)
+	for i := range table {
+		table[i] = -1
+	}
+	table[0] = 0
+	done := 1
+	for depth := int8(0); done < len(table); depth++ {
+		for i, x := range table {
+			if x != depth {
+				continue
+			}
+			a, b := i/n2, i%n2
+			for _, m := range moves {
+				j := move1(a, m)*n2 + move2(b, m)
+				if table[j] == -1 {
+					table[j] = depth + 1
+					done++
+				}
+			}
+		}
+	}
+	return table
+}
+
+//PHASE1_EXTRA is how many phase 1 depths past the one giving the first solution are searched for a shorter solution,
+//each takes about ten times as long as the one before
+const PHASE1_EXTRA = 2
+
+//DEFAULT_MAX_LENGTH is the longest solution looked for through the solver registry when no maximum is given
+const DEFAULT_MAX_LENGTH = 23
+
+func init() {
+	solver.Register("twophase", func(ctx context.Context, c *bytecube.Cube, o solver.Options) (solver.Solver, error) {
+		if o.MaxLength == 0 {
+			o.MaxLength = DEFAULT_MAX_LENGTH
+		}
+		s, err := NewSolver(c, o.Target, o.MaxLength)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	})
+}
+
+type Solver struct {
+	cube      *cubie.Cube
+	maxLength int
+	moves     []int
+	best      []int
+	stop      *solver.Stopper
+	observer  solver.Observer
+}
+
+//NewSolver returns a solver that will look for a solution of at most maxLength moves from c to target, or to the solved
+//cube when target is "". The first call generates the tables the searches share which takes a few seconds.
+func NewSolver(c *bytecube.Cube, target string, maxLength int) (*Solver, error) {
+	if target != "" {
+		relative, err := solver.Relative(c, target)
+		if err != nil {
+			return nil, err
+		}
+		c = relative
+	}
+	cc, err := cubie.FromBytecube(c)
+	if err != nil {
+		return nil, err
+	}
+	if err = cc.Verify(); err != nil {
+		return nil, err
+	}
+	tablesOnce.Do(initTables)
+	s := new(Solver)
+	s.cube = cc
+	s.maxLength = maxLength
+	return s, nil
+}
+
+//SetObserver sets the observer that receives the progress of the solve, by default nothing is reported
+func (s *Solver) SetObserver(o solver.Observer) {
+	s.observer = o
+}
+
+//Solve returns a solution in official notation, the solution for an already solved cube is ""
+func (s *Solver) Solve() (string, error) {
+	return s.SolveContext(context.Background())
+}
+
+//SolveContext stops the search and returns a *solver.CancelError when ctx is canceled or its deadline passes
+func (s *Solver) SolveContext(ctx context.Context) (string, error) {
+	s.stop = solver.NewStopper(ctx)
+	s.moves = make([]int, s.maxLength)
+	s.best = nil
+	twist, flip, slice := s.cube.Twist(), s.cube.Flip(), udSlice(s.cube)
+	//a longer phase 1 can still give a shorter solution while it is shorter than the best found
+	last := s.maxLength
+	for depth := 0; depth <= last && (s.best == nil || depth < len(s.best)); depth++ {
+		s.observer.Notify(solver.Event{Kind: solver.DepthStarted, Depth: depth})
+		found := s.best != nil
+		s.phase1(twist, flip, slice, 0, depth)
+		if err := s.stop.Err(); err != nil {
+			return "", err
+		}
+		if !found && s.best != nil && depth+PHASE1_EXTRA < last {
+			last = depth + PHASE1_EXTRA
+		}
+	}
+	if s.best == nil {
+		return "", ErrNoSolution
+	}
+	solution := movesString(s.best)
+	s.observer.Notify(solver.Event{Kind: solver.SolutionFound, Depth: len(s.best), Solution: solution})
+	return solution, nil
+}
+
+//phase1 searches for phase 1 solutions of exactly maxDepth moves and finishes each with phase 2
+func (s *Solver) phase1(twist, flip, slice, depth, maxDepth int) {
+	if s.stop.Stopped() {
+		return
+	}
+	if depth == maxDepth {
+		if twist == 0 && flip == 0 && slice == 0 {
+			if depth > 0 && isPhase2Move(s.moves[depth-1]) {
+				//a shorter phase 1 solution exists and has already been tried
+				return
+			}
+			s.startPhase2(depth)
+		}
+		return
+	}
+	if phase1Distance(twist, flip, slice) > maxDepth-depth {
+		return
+	}
+	for m := 0; m < N_MOVE; m++ {
+		if depth > 0 && skipMove(s.moves[depth-1], m) {
+			continue
+		}
+		s.moves[depth] = m
+		s.phase1(twistMove[twist][m], flipMove[flip][m], sliceMove[slice][m], depth+1, maxDepth)
+	}
+}
+
+func phase1Distance(twist, flip, slice int) int {
+	a := sliceTwistPrune[slice*N_TWIST+twist]
+	b := sliceFlipPrune[slice*N_FLIP+flip]
+	if a > b {
+		return int(a)
+	}
+	return int(b)
+}
+
+func phase2Distance(corner, edge, slice int) int {
+	a := cornerSlicePrune[slice*N_CORNER_PERM+corner]
+	b := edgeSlicePrune[slice*N_EDGE_PERM+edge]
+	if a > b {
+		return int(a)
+	}
+	return int(b)
+}
+
+//startPhase2 keeps the shortest phase 2 solution after the phase 1 solution of depth moves when it is the best so far
+func (s *Solver) startPhase2(depth int) {
+	c := *s.cube
+	for _, m := range s.moves[:depth] {
+		c.Move(m)
+	}
+	longest := s.maxLength
+	if s.best != nil {
+		longest = len(s.best) - 1
+	}
+	corner, edge, slice := cornerPerm(&c), edgePerm(&c), slicePerm(&c)
+	for length := phase2Distance(corner, edge, slice); depth+length <= longest; length++ {
+		if s.phase2(corner, edge, slice, depth, depth+length) {
+			s.best = append([]int{}, s.moves[:depth+length]...)
+			return
+		}
+	}
+}
+
+func (s *Solver) phase2(corner, edge, slice, depth, maxDepth int) bool {
+	if s.stop.Stopped() {
+		return false
+	}
+	if depth == maxDepth {
+		return corner == 0 && edge == 0 && slice == 0
+	}
+	if phase2Distance(corner, edge, slice) > maxDepth-depth {
+		return false
+	}
+	for _, m := range phase2Moves {
+		if depth > 0 && skipMove(s.moves[depth-1], m) {
+			continue
+		}
+		s.moves[depth] = m
+		if s.phase2(cornerPermMove[corner][m], edgePermMove[edge][m], slicePermMove[slice][m], depth+1, maxDepth) {
+			return true
+		}
+	}
+	return false
+}
+
+func isPhase2Move(m int) bool {
+	for _, x := range phase2Moves {
+		if x == m {
+			return true
+		}
+	}
+	return false
+}
+
+//skipMove is true for turning the same face twice in a row and for turning opposite faces in the non-canonical order
+func skipMove(last, m int) bool {
+	return last/3 == m/3 || last/3 == m/3+3
+}
+
+func movesString(moves []int) string {
+	steps := make([]string, len(moves))
+	for i, m := range moves {
+		steps[i] = cubie.MoveName(m)
+	}
+	return strings.Join(steps, " ")
+}
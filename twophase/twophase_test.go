@@ -0,0 +1,151 @@
+package twophase
+
+import (
+	"context"
+	"errors"
+	"github.com/davidafox/rubikscubesolver/bytecube"
+	"github.com/davidafox/rubikscubesolver/cubie"
+	"github.com/davidafox/rubikscubesolver/rubikscuberunner"
+	"github.com/davidafox/rubikscubesolver/solver"
+	"strings"
+	"testing"
+)
+
+var solvedCube = "000000000111111111222222222333333333444444444555555555"
+
+func TestCoordinates(t *testing.T) {
+	c := cubie.NewCube()
+	for i := 0; i < N_SLICE; i++ {
+		setUDSlice(c, i)
+		if udSlice(c) != i {
+			t.Error("Failed udSlice got: ", udSlice(c), " expected: ", i)
+		}
+	}
+	for i := 0; i < N_CORNER_PERM; i += 97 {
+		setCornerPerm(c, i)
+		if cornerPerm(c) != i {
+			t.Error("Failed cornerPerm got: ", cornerPerm(c), " expected: ", i)
+		}
+	}
+	for i := 0; i < N_SLICE_PERM; i++ {
+		setSlicePerm(c, i)
+		if slicePerm(c) != i {
+			t.Error("Failed slicePerm got: ", slicePerm(c), " expected: ", i)
+		}
+	}
+}
+
+func TestSolve(t *testing.T) {
+	data := []string{
+		"",
+		"R",
+		"R U L F L",
+		"F R B L U R F' R2",
+		"R U' B' L F R' U2 F2 L' D R U L'",
+		"D2 R' F2 U B2 L' D' F R2 U2 B L2 F' D R' U' L B2 F D'",
+		"B' R2 D F' L U2 R B D2 L' F U' R2 B' D L2 F R' U B2",
+	}
+	for _, x := range data {
+		c, _ := bytecube.NewCube(solvedCube)
+		r := rubikscuberunner.NewOfficialRunner(c)
+		if x != "" {
+			r.Run(x)
+		}
+		s, err := NewSolver(c, "", 23)
+		if err != nil {
+			t.Error("Failed NewSolver for ", x, " got error: ", err)
+			continue
+		}
+		result, err := s.Solve()
+		if err != nil {
+			t.Error("Failed Solve for ", x, " got error: ", err)
+			continue
+		}
+		if len(strings.Fields(result)) > 23 {
+			t.Error("Failed Solve for ", x, " solution too long: ", result)
+		}
+		if result != "" {
+			r.Run(result)
+		}
+		if !c.Solved() {
+			t.Error("Failed to solve ", x, " with ", result, " got: ", c.String())
+		}
+	}
+}
+
+func TestSolveShort(t *testing.T) {
+	//a longer phase 1 finds these scrambles undone, the first phase 1 solution gives a longer solution
+	for _, x := range []string{"R U", "R U F' D2 L B", "F R' U2 L D' B2 R"} {
+		c, _ := bytecube.NewCube(solvedCube)
+		rubikscuberunner.NewOfficialRunner(c).Run(x)
+		s, err := NewSolver(c, "", 23)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := s.Solve()
+		if err != nil || len(strings.Fields(result)) > len(strings.Fields(x)) {
+			t.Error("Failed Solve of ", x, " got: ", result, err)
+		}
+	}
+}
+
+func TestSolveTarget(t *testing.T) {
+	target, _ := bytecube.NewCube(solvedCube)
+	rubikscuberunner.NewOfficialRunner(target).Run("R U F'")
+	c, _ := bytecube.NewCube(solvedCube)
+	r := rubikscuberunner.NewOfficialRunner(c)
+	r.Run("D2 L B'")
+	s, err := NewSolver(c, target.String(), 23)
+	if err != nil {
+		t.Fatal("Failed NewSolver with a target got error: ", err)
+	}
+	result, err := s.Solve()
+	if err != nil {
+		t.Fatal("Failed Solve with a target got error: ", err)
+	}
+	if result != "" {
+		r.Run(result)
+	}
+	if c.String() != target.String() {
+		t.Error("Failed Solve with a target ", result, " got: ", c.String(), " expected: ", target.String())
+	}
+	var targetErr *solver.TargetError
+	if _, err := NewSolver(c, solvedCube[:53], 23); !errors.As(err, &targetErr) {
+		t.Error("Failed NewSolver with a short target got: ", err)
+	}
+}
+
+func TestNewSolverUnsolvable(t *testing.T) {
+	data := []struct {
+		state string
+		err   error
+	}{
+		//twisted URF corner
+		{"003000000111111111222222222433333333444444440555555555", cubie.ErrTwistedCorner},
+		//flipped UF edge
+		{"040000000111111111222222222333333333444444404555555555", cubie.ErrFlippedEdge},
+		//two stickers of the same color on an edge
+		{"030000000111111111222222222333333333444444404555555555", cubie.ErrInvalidCube},
+	}
+	for _, x := range data {
+		c, _ := bytecube.NewCube(x.state)
+		_, err := NewSolver(c, "", 23)
+		if err != x.err {
+			t.Error("Failed NewSolver for ", x.state, " got: ", err, " expected: ", x.err)
+		}
+	}
+}
+
+func TestSolveContext(t *testing.T) {
+	c, _ := bytecube.NewCube(solvedCube)
+	rubikscuberunner.NewOfficialRunner(c).Run("D2 R' F2 U B2 L' D' F R2 U2 B L2 F' D R' U' L B2 F D'")
+	//no solution of 12 moves exists so only the context can stop the search
+	s, _ := NewSolver(c, "", 12)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := s.SolveContext(ctx)
+	var cancelErr *solver.CancelError
+	if !errors.As(err, &cancelErr) || !errors.Is(err, context.Canceled) {
+		t.Error("Failed SolveContext got: ", result, err, " expected: ", context.Canceled)
+	}
+}